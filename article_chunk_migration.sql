@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS article_chunk (
+  id bigint PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  is_deleted boolean NOT NULL DEFAULT false,
+  created_by bigint,
+  updated_by bigint,
+  article_id bigint NOT NULL,
+  position integer NOT NULL,
+  heading_path text NOT NULL DEFAULT '',
+  content text NOT NULL,
+  content_hash varchar(64) NOT NULL,
+  token_count integer NOT NULL DEFAULT 0,
+  embedding vector(1024)
+);
+
+CREATE INDEX IF NOT EXISTS article_chunk_article_id_idx
+  ON article_chunk (article_id, position);
+
+CREATE INDEX IF NOT EXISTS article_chunk_embedding_idx
+  ON article_chunk USING hnsw (embedding vector_cosine_ops);
@@ -0,0 +1 @@
+ALTER TABLE article ADD COLUMN IF NOT EXISTS meta_description text NOT NULL DEFAULT '';
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS article_revision (
+  id bigint PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  is_deleted boolean NOT NULL DEFAULT false,
+  created_by bigint,
+  updated_by bigint,
+  article_id bigint NOT NULL,
+  version integer NOT NULL,
+  title text,
+  content text,
+  tag text,
+  source text NOT NULL,
+  editor text,
+  restored_from integer
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS article_revision_article_version_uidx
+  ON article_revision (article_id, version);
@@ -0,0 +1,23 @@
+ALTER TABLE article
+  ADD COLUMN IF NOT EXISTS slug text;
+
+CREATE UNIQUE INDEX IF NOT EXISTS article_slug_uidx
+  ON article (slug)
+  WHERE slug IS NOT NULL AND slug <> '';
+
+CREATE TABLE IF NOT EXISTS article_slug_redirect (
+  id bigint PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  is_deleted boolean NOT NULL DEFAULT false,
+  created_by bigint,
+  updated_by bigint,
+  old_slug text NOT NULL,
+  article_id bigint NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS article_slug_redirect_old_slug_uidx
+  ON article_slug_redirect (old_slug);
+
+CREATE INDEX IF NOT EXISTS article_slug_redirect_article_id_idx
+  ON article_slug_redirect (article_id);
@@ -0,0 +1,18 @@
+ALTER TABLE article
+  ADD COLUMN IF NOT EXISTS status text,
+  ADD COLUMN IF NOT EXISTS publish_at timestamptz;
+
+UPDATE article
+  SET status = CASE WHEN is_active THEN 'published' ELSE 'draft' END
+  WHERE status IS NULL;
+
+UPDATE article
+  SET publish_at = created_at
+  WHERE publish_at IS NULL AND status = 'published';
+
+ALTER TABLE article
+  ALTER COLUMN status SET DEFAULT 'draft',
+  ALTER COLUMN status SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS article_status_publish_at_idx
+  ON article (status, publish_at);
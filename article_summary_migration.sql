@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS article_summary (
+  id bigint PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  is_deleted boolean NOT NULL DEFAULT false,
+  created_by bigint,
+  updated_by bigint,
+  article_id bigint NOT NULL,
+  short_summary text NOT NULL DEFAULT '',
+  long_summary text NOT NULL DEFAULT '',
+  reading_minutes integer NOT NULL DEFAULT 0,
+  word_count integer NOT NULL DEFAULT 0,
+  model varchar(128) NOT NULL DEFAULT '',
+  prompt_version integer NOT NULL DEFAULT 0,
+  content_hash varchar(64) NOT NULL DEFAULT ''
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS article_summary_article_id_uidx
+  ON article_summary (article_id);
+
+-- 原 Redis 中的 articleSummary 不迁移，部署后调用 POST /v1/articles/summary/regenerate 生成
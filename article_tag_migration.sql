@@ -0,0 +1,48 @@
+CREATE TABLE IF NOT EXISTS tag (
+  id bigint PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  is_deleted boolean NOT NULL DEFAULT false,
+  created_by bigint,
+  updated_by bigint,
+  name text NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS tag_name_uidx
+  ON tag (name);
+
+CREATE TABLE IF NOT EXISTS article_tag (
+  article_id bigint NOT NULL,
+  tag_id bigint NOT NULL,
+  PRIMARY KEY (article_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS article_tag_tag_id_idx
+  ON article_tag (tag_id);
+
+-- 拆分 article.tag 中逗号分隔的旧数据
+-- id 按 snowflake 格式生成（epoch 1288834974657，节点号 2 与应用节点 1 区分）
+WITH names AS (
+  SELECT DISTINCT btrim(name) AS name
+  FROM article
+  CROSS JOIN LATERAL regexp_split_to_table(article.tag, '[,，]') AS name
+  WHERE article.tag IS NOT NULL AND btrim(name) <> ''
+),
+numbered AS (
+  SELECT name, row_number() OVER (ORDER BY name) AS rn
+  FROM names
+  WHERE name NOT IN (SELECT name FROM tag)
+)
+INSERT INTO tag (id, created_at, updated_at, is_deleted, name)
+SELECT ((floor(extract(epoch FROM now()) * 1000)::bigint - 1288834974657 + rn / 4096) << 22)
+         | (2 << 12) | (rn % 4096),
+       now(), now(), false, name
+FROM numbered;
+
+INSERT INTO article_tag (article_id, tag_id)
+SELECT DISTINCT article.id, tag.id
+FROM article
+CROSS JOIN LATERAL regexp_split_to_table(article.tag, '[,，]') AS name
+JOIN tag ON tag.name = btrim(name)
+WHERE article.tag IS NOT NULL
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS article_translation (
+  id bigint PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  is_deleted boolean NOT NULL DEFAULT false,
+  created_by bigint,
+  updated_by bigint,
+  article_id bigint NOT NULL,
+  locale varchar(16) NOT NULL,
+  title text NOT NULL DEFAULT '',
+  content text NOT NULL DEFAULT '',
+  model varchar(128) NOT NULL DEFAULT '',
+  edited boolean NOT NULL DEFAULT false,
+  source_updated_at timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS article_translation_article_locale_uidx
+  ON article_translation (article_id, locale);
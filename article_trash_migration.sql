@@ -0,0 +1,11 @@
+ALTER TABLE article
+  ADD COLUMN IF NOT EXISTS trashed_at timestamptz;
+
+-- 已软删除的文章以最后更新时间作为移入回收站的时间
+UPDATE article
+  SET trashed_at = updated_at
+  WHERE is_deleted = true AND trashed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS article_trashed_at_idx
+  ON article (trashed_at)
+  WHERE is_deleted = true;
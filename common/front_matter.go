@@ -0,0 +1,153 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FrontMatter Markdown 文件头部的 YAML 元数据，字段与 SyncToDify 写出的一致
+type FrontMatter struct {
+	ID     string          `yaml:"id,omitempty"`
+	Title  string          `yaml:"title"`
+	Slug   string          `yaml:"slug,omitempty"`
+	Date   string          `yaml:"date,omitempty"`
+	Tags   FrontMatterTags `yaml:"tags,omitempty,flow"`
+	Status string          `yaml:"status,omitempty"`
+	Draft  *bool           `yaml:"draft,omitempty"`
+}
+
+// FrontMatterTags 兼容 `tags: [a, b]`、列表以及 `tags: a,b` 三种写法
+type FrontMatterTags []string
+
+func (t *FrontMatterTags) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.SequenceNode:
+		var tags []string
+		if err := value.Decode(&tags); err != nil {
+			return err
+		}
+		*t = tags
+	case yaml.ScalarNode:
+		*t = splitFrontMatterList(value.Value)
+	default:
+		return fmt.Errorf("invalid tags at line %d", value.Line)
+	}
+	return nil
+}
+
+var frontMatterDateLayouts = []string{
+	"2006-01-02 15:04:05",
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseDate 解析 date 字段，未带时区的按本地时间处理
+func (fm FrontMatter) ParseDate() (*time.Time, error) {
+	if fm.Date == "" {
+		return nil, nil
+	}
+	for _, layout := range frontMatterDateLayouts {
+		if t, err := time.ParseInLocation(layout, fm.Date, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid date: %s", fm.Date)
+}
+
+// ParseFrontMatter 拆分 Markdown 的 front matter 与正文；没有 front matter 时原样返回正文
+func ParseFrontMatter(data []byte) (FrontMatter, string, error) {
+	var fm FrontMatter
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return fm, text, nil
+	}
+	end := strings.Index(text[4:], "\n---")
+	if end < 0 {
+		return fm, text, nil
+	}
+	header := text[4 : 4+end]
+	body := text[4+end+len("\n---"):]
+	// 去掉结束分隔符所在行的剩余部分以及正文前的空行
+	if i := strings.IndexByte(body, '\n'); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = ""
+	}
+	body = strings.TrimLeft(body, "\n")
+
+	if err := yaml.Unmarshal([]byte(header), &fm); err != nil {
+		// SyncToDify 写出的 title 未加引号，标题里含冒号时不是合法 YAML，退回逐行解析
+		fm = parseFrontMatterLines(header)
+		if fm.Title == "" && fm.ID == "" && fm.Slug == "" {
+			return fm, body, err
+		}
+	}
+	fm.ID = strings.TrimSpace(fm.ID)
+	fm.Title = strings.TrimSpace(fm.Title)
+	fm.Slug = strings.TrimSpace(fm.Slug)
+	fm.Status = strings.TrimSpace(fm.Status)
+	return fm, body, nil
+}
+
+// MarshalFrontMatter 生成带分隔符的 front matter，fm 可以是 FrontMatter 或其他生成器的 front matter 结构
+func MarshalFrontMatter(fm interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(fm); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	buf.WriteString("---\n\n")
+	return buf.Bytes(), nil
+}
+
+func parseFrontMatterLines(header string) FrontMatter {
+	var fm FrontMatter
+	for _, line := range strings.Split(header, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"'`)
+		switch strings.TrimSpace(key) {
+		case "id":
+			fm.ID = value
+		case "title":
+			fm.Title = value
+		case "slug":
+			fm.Slug = value
+		case "date":
+			fm.Date = value
+		case "status":
+			fm.Status = value
+		case "tags":
+			fm.Tags = splitFrontMatterList(value)
+		}
+	}
+	return fm
+}
+
+func splitFrontMatterList(value string) []string {
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == '，'
+	})
+	result := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = strings.Trim(strings.TrimSpace(field), `"'`); field != "" {
+			result = append(result, field)
+		}
+	}
+	return result
+}
@@ -0,0 +1,108 @@
+package common
+
+import "strings"
+
+// 行差异类型
+const (
+	DiffEqual  = "equal"
+	DiffInsert = "insert"
+	DiffDelete = "delete"
+)
+
+// diffMaxCells LCS 表的最大单元数（int32，约 16MB），超过时中间部分按整体替换输出
+const diffMaxCells = 4_000_000
+
+// DiffLine 行级差异
+type DiffLine struct {
+	Op      string `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"oldLine,omitempty"`
+	NewLine int    `json:"newLine,omitempty"`
+}
+
+// DiffLines 基于最长公共子序列计算两段文本的行级差异
+func DiffLines(oldText, newText string) []DiffLine {
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+
+	// 先去掉公共前缀和后缀，缩小 LCS 表的规模
+	prefix := 0
+	for prefix < len(oldLines) && prefix < len(newLines) && oldLines[prefix] == newLines[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(oldLines)-prefix && suffix < len(newLines)-prefix &&
+		oldLines[len(oldLines)-1-suffix] == newLines[len(newLines)-1-suffix] {
+		suffix++
+	}
+
+	diff := make([]DiffLine, 0, len(oldLines)+len(newLines))
+	for i := 0; i < prefix; i++ {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: oldLines[i], OldLine: i + 1, NewLine: i + 1})
+	}
+
+	a := oldLines[prefix : len(oldLines)-suffix]
+	b := newLines[prefix : len(newLines)-suffix]
+
+	if len(a)*len(b) > diffMaxCells {
+		for i, line := range a {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: line, OldLine: prefix + i + 1})
+		}
+		for j, line := range b {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: line, NewLine: prefix + j + 1})
+		}
+		return appendDiffSuffix(diff, oldLines, newLines, suffix)
+	}
+
+	// lcs[i][j] 表示 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: a[i], OldLine: prefix + i + 1, NewLine: prefix + j + 1})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i], OldLine: prefix + i + 1})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j], NewLine: prefix + j + 1})
+			j++
+		}
+	}
+
+	return appendDiffSuffix(diff, oldLines, newLines, suffix)
+}
+
+// appendDiffSuffix 追加两段文本末尾 suffix 行的公共后缀
+func appendDiffSuffix(diff []DiffLine, oldLines, newLines []string, suffix int) []DiffLine {
+	oldOffset := len(oldLines) - suffix
+	newOffset := len(newLines) - suffix
+	for k := 0; k < suffix; k++ {
+		diff = append(diff, DiffLine{Op: DiffEqual, Text: oldLines[oldOffset+k], OldLine: oldOffset + k + 1, NewLine: newOffset + k + 1})
+	}
+	return diff
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+}
@@ -0,0 +1,206 @@
+package common
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// HeadingPathSeparator 标题路径的分隔符
+const HeadingPathSeparator = " > "
+
+// MarkdownChunk 切分后的 Markdown 片段
+type MarkdownChunk struct {
+	HeadingPath string
+	Content     string
+	TokenCount  int
+}
+
+// Hash 文章标题、标题路径与内容的 sha256，用于判断片段是否需要重新生成向量
+// 生成向量的文本包含文章标题，标题变化时所有片段都要重新生成
+func (chunk MarkdownChunk) Hash(title string) string {
+	sum := sha256.Sum256([]byte(title + "\n" + chunk.HeadingPath + "\n" + chunk.Content))
+	return hex.EncodeToString(sum[:])
+}
+
+var markdownHeadingPattern = regexp.MustCompile(`^(#{1,6})\s+(.+?)\s*#*\s*$`)
+
+type markdownHeading struct {
+	level int
+	text  string
+}
+
+type markdownSection struct {
+	headingPath string
+	lines       []string
+}
+
+// ChunkMarkdown 先按 Markdown 标题切分章节，超过 maxTokens 的章节再按段落、行、字符切分
+// 代码块内的 # 不视为标题，代码块尽量保持完整
+func ChunkMarkdown(content string, maxTokens int) []MarkdownChunk {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var sections []markdownSection
+	var headings []markdownHeading
+	current := markdownSection{}
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence {
+			if match := markdownHeadingPattern.FindStringSubmatch(line); match != nil {
+				sections = append(sections, current)
+				level := len(match[1])
+				for len(headings) > 0 && headings[len(headings)-1].level >= level {
+					headings = headings[:len(headings)-1]
+				}
+				headings = append(headings, markdownHeading{level: level, text: match[2]})
+				texts := make([]string, 0, len(headings))
+				for _, heading := range headings {
+					texts = append(texts, heading.text)
+				}
+				// 标题本身记录在 HeadingPath 中，不重复写入片段内容
+				current = markdownSection{headingPath: strings.Join(texts, HeadingPathSeparator)}
+				continue
+			}
+		}
+		current.lines = append(current.lines, line)
+	}
+	sections = append(sections, current)
+
+	var chunks []MarkdownChunk
+	for _, section := range sections {
+		text := strings.TrimSpace(strings.Join(section.lines, "\n"))
+		// 只有标题没有正文的章节不单独成块，其标题会出现在下级章节的路径中
+		if text == "" {
+			continue
+		}
+		for _, part := range splitByTokenBudget(text, maxTokens) {
+			chunks = append(chunks, MarkdownChunk{
+				HeadingPath: section.headingPath,
+				Content:     part,
+				TokenCount:  EstimateTokens(part),
+			})
+		}
+	}
+	return chunks
+}
+
+// splitByTokenBudget 按段落贪心合并，单个段落超出预算时按行、再按字符切分
+func splitByTokenBudget(text string, maxTokens int) []string {
+	if maxTokens <= 0 || EstimateTokens(text) <= maxTokens {
+		return []string{text}
+	}
+
+	var parts []string
+	var current strings.Builder
+	currentTokens := 0
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			parts = append(parts, s)
+		}
+		current.Reset()
+		currentTokens = 0
+	}
+	add := func(block string, separator string) {
+		tokens := EstimateTokens(block)
+		if currentTokens > 0 && currentTokens+tokens > maxTokens {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString(separator)
+		}
+		current.WriteString(block)
+		currentTokens += tokens
+	}
+
+	for _, block := range markdownBlocks(text) {
+		if EstimateTokens(block) <= maxTokens {
+			add(block, "\n\n")
+			continue
+		}
+		flush()
+		for _, line := range strings.Split(block, "\n") {
+			if EstimateTokens(line) <= maxTokens {
+				add(line, "\n")
+				continue
+			}
+			flush()
+			for _, piece := range splitRunesByTokens(line, maxTokens) {
+				add(piece, "")
+				flush()
+			}
+		}
+		flush()
+	}
+	flush()
+	return parts
+}
+
+// markdownBlocks 按空行拆分段落，代码块内的空行不拆分
+func markdownBlocks(text string) []string {
+	var blocks []string
+	var current []string
+	inFence := false
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if trimmed == "" && !inFence {
+			if len(current) > 0 {
+				blocks = append(blocks, strings.Join(current, "\n"))
+				current = nil
+			}
+			continue
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 {
+		blocks = append(blocks, strings.Join(current, "\n"))
+	}
+	return blocks
+}
+
+// splitRunesByTokens 按字符切分超长的行，不会截断多字节字符
+func splitRunesByTokens(text string, maxTokens int) []string {
+	var parts []string
+	start := 0
+	cost := 0.0
+	for i, r := range text {
+		c := runeTokenCost(r)
+		if cost+c > float64(maxTokens) && i > start {
+			parts = append(parts, text[start:i])
+			start, cost = i, 0
+		}
+		cost += c
+	}
+	if start < len(text) {
+		parts = append(parts, text[start:])
+	}
+	return parts
+}
+
+// EstimateTokens 粗略估算 token 数：中日韩字符按 1 个计，其他非空白字符按 4 个 1 token 计
+func EstimateTokens(text string) int {
+	cost := 0.0
+	for _, r := range text {
+		cost += runeTokenCost(r)
+	}
+	return int(cost + 0.999)
+}
+
+func runeTokenCost(r rune) float64 {
+	switch {
+	case unicode.IsSpace(r):
+		return 0
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+		return 1
+	default:
+		return 0.25
+	}
+}
@@ -0,0 +1,137 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// SplitMarkdownSections 按标题把 Markdown 切分为章节，标题行保留在所属章节中
+// 超过 maxTokens 的章节再按段落切分；各部分以空行连接即可还原文档
+func SplitMarkdownSections(content string, maxTokens int) []string {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	var sections []string
+	var current []string
+	flush := func() {
+		if text := strings.Trim(strings.Join(current, "\n"), "\n"); strings.TrimSpace(text) != "" {
+			sections = append(sections, text)
+		}
+		current = nil
+	}
+	inFence := false
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && markdownHeadingPattern.MatchString(line) {
+			flush()
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	var parts []string
+	for _, section := range sections {
+		if maxTokens <= 0 || EstimateTokens(section) <= maxTokens {
+			parts = append(parts, section)
+			continue
+		}
+		// 按段落合并，代码块保持完整，单个段落超出预算时不再切分
+		var merged []string
+		tokens := 0
+		for _, block := range markdownBlocks(section) {
+			blockTokens := EstimateTokens(block)
+			if tokens > 0 && tokens+blockTokens > maxTokens {
+				parts = append(parts, strings.Join(merged, "\n\n"))
+				merged, tokens = nil, 0
+			}
+			merged = append(merged, block)
+			tokens += blockTokens
+		}
+		if len(merged) > 0 {
+			parts = append(parts, strings.Join(merged, "\n\n"))
+		}
+	}
+	return parts
+}
+
+var (
+	markdownInlineCodePattern = regexp.MustCompile("`[^`\n]+`")
+	markdownLinkDestPattern   = regexp.MustCompile(`\]\(([^)\s]+)((?:\s+"[^"]*")?)\)`)
+	markdownBareURLPattern    = regexp.MustCompile(`https?://[^\s<>()\[\]]+`)
+	protectedPlaceholder      = regexp.MustCompile(`⟦\d+⟧`)
+)
+
+// ProtectMarkdown 将代码块、行内代码和链接地址替换为 ⟦n⟧ 占位符，避免翻译时被修改
+// 返回替换后的文本和按序号排列的原文
+func ProtectMarkdown(text string) (string, []string) {
+	var kept []string
+	keep := func(s string) string {
+		kept = append(kept, s)
+		return fmt.Sprintf("⟦%d⟧", len(kept)-1)
+	}
+
+	var lines []string
+	var fence []string
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimSpace(line)
+		isFence := strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+		switch {
+		case fence != nil:
+			fence = append(fence, line)
+			if isFence {
+				lines = append(lines, keep(strings.Join(fence, "\n")))
+				fence = nil
+			}
+		case isFence:
+			fence = []string{line}
+		default:
+			lines = append(lines, line)
+		}
+	}
+	// 未闭合的代码块一直延续到结尾
+	if fence != nil {
+		lines = append(lines, keep(strings.Join(fence, "\n")))
+	}
+	text = strings.Join(lines, "\n")
+
+	text = markdownInlineCodePattern.ReplaceAllStringFunc(text, keep)
+	text = markdownLinkDestPattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := markdownLinkDestPattern.FindStringSubmatch(match)
+		return "](" + keep(groups[1]) + groups[2] + ")"
+	})
+	text = markdownBareURLPattern.ReplaceAllStringFunc(text, keep)
+	return text, kept
+}
+
+// OnlyPlaceholders 文本除占位符外没有需要翻译的内容
+func OnlyPlaceholders(text string) bool {
+	return strings.TrimSpace(protectedPlaceholder.ReplaceAllString(text, "")) == ""
+}
+
+// RestoreMarkdown 将占位符替换回原文，占位符缺失或重复时返回错误
+func RestoreMarkdown(text string, kept []string) (string, error) {
+	counts := make([]int, len(kept))
+	var unknown string
+	restored := protectedPlaceholder.ReplaceAllStringFunc(text, func(match string) string {
+		var n int
+		fmt.Sscanf(match, "⟦%d⟧", &n)
+		if n < 0 || n >= len(kept) {
+			unknown = match
+			return match
+		}
+		counts[n]++
+		return kept[n]
+	})
+	if unknown != "" {
+		return "", fmt.Errorf("unknown placeholder %s", unknown)
+	}
+	for n, count := range counts {
+		if count != 1 {
+			return "", fmt.Errorf("placeholder ⟦%d⟧ appears %d times", n, count)
+		}
+	}
+	return restored, nil
+}
@@ -0,0 +1,153 @@
+package common
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// SortKey 游标分页的排序键，最后一个键必须唯一（通常为 id）
+type SortKey struct {
+	Column string
+	Desc   bool
+}
+
+// PageRequest 游标分页参数
+// 请求中带有 cursor 参数（可为空）时启用游标分页，响应为 Page 结构
+type PageRequest struct {
+	Enabled bool
+	Limit   int
+	After   []string
+}
+
+// Page 统一的游标分页响应
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"nextCursor"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+type cursorPayload struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// ParsePageRequest 解析 cursor / limit 参数
+func ParsePageRequest(c *fiber.Ctx, keys []SortKey, defaultLimit int, maxLimit int) (PageRequest, error) {
+	req := PageRequest{Enabled: c.Context().QueryArgs().Has("cursor")}
+	if req.Enabled {
+		req.Limit = defaultLimit
+	}
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return req, NewBusinessException(5000, "Invalid limit value")
+		}
+		req.Limit = limit
+	}
+	if req.Enabled && maxLimit > 0 && req.Limit > maxLimit {
+		req.Limit = maxLimit
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		values, err := decodeCursor(cursor, keys)
+		if err != nil {
+			return req, NewBusinessException(5000, "Invalid cursor")
+		}
+		req.After = values
+	}
+	return req, nil
+}
+
+// ApplyKeyset 按排序键排序，并从游标之后开始查询，多取一条用于判断是否还有下一页
+func ApplyKeyset(query *gorm.DB, keys []SortKey, req PageRequest) *gorm.DB {
+	for _, key := range keys {
+		direction := "ASC"
+		if key.Desc {
+			direction = "DESC"
+		}
+		query = query.Order(key.Column + " " + direction)
+	}
+
+	if len(req.After) == len(keys) {
+		// (k1 < v1) OR (k1 = v1 AND k2 < v2) OR ...
+		clauses := make([]string, 0, len(keys))
+		args := make([]interface{}, 0, len(keys)*(len(keys)+1)/2)
+		for i, key := range keys {
+			parts := make([]string, 0, i+1)
+			for j := 0; j < i; j++ {
+				parts = append(parts, keys[j].Column+" = ?")
+				args = append(args, req.After[j])
+			}
+			operator := ">"
+			if key.Desc {
+				operator = "<"
+			}
+			parts = append(parts, key.Column+" "+operator+" ?")
+			args = append(args, req.After[i])
+			clauses = append(clauses, "("+strings.Join(parts, " AND ")+")")
+		}
+		query = query.Where("("+strings.Join(clauses, " OR ")+")", args...)
+	}
+
+	if req.Enabled {
+		return query.Limit(req.Limit + 1)
+	}
+	if req.Limit > 0 {
+		return query.Limit(req.Limit)
+	}
+	return query
+}
+
+// NewPage 根据多取的一条记录判断是否有下一页，并生成下一页游标
+func NewPage[T any](items []T, keys []SortKey, req PageRequest, cursorOf func(T) []string) Page[T] {
+	page := Page[T]{Items: items}
+	if len(items) > req.Limit {
+		page.Items = items[:req.Limit]
+		page.HasMore = true
+		page.NextCursor = encodeCursor(keys, cursorOf(page.Items[len(page.Items)-1]))
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	return page
+}
+
+// sortSignature 用于校验游标与当前排序方式一致
+func sortSignature(keys []SortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Desc {
+			parts = append(parts, key.Column+":desc")
+		} else {
+			parts = append(parts, key.Column+":asc")
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func encodeCursor(keys []SortKey, values []string) string {
+	data, _ := json.Marshal(cursorPayload{Sort: sortSignature(keys), Values: values})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(cursor string, keys []SortKey) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	if payload.Sort != sortSignature(keys) || len(payload.Values) != len(keys) {
+		return nil, fmt.Errorf("cursor does not match current sort")
+	}
+	return payload.Values, nil
+}
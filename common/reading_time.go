@@ -0,0 +1,47 @@
+package common
+
+import (
+	"math"
+	"regexp"
+	"unicode"
+)
+
+const (
+	// cjkCharsPerMinute 中日韩文字的阅读速度（字/分钟）
+	cjkCharsPerMinute = 300
+	// wordsPerMinute 英文等以空格分词的文字的阅读速度（词/分钟）
+	wordsPerMinute = 200
+)
+
+// markdownLinkTargetPattern 链接和图片的地址，不计入阅读字数
+var markdownLinkTargetPattern = regexp.MustCompile(`\]\([^)]*\)`)
+
+// ReadingStats 按正文统计字数和预计阅读时间（分钟，向上取整）
+// 中日韩文字按字计数，其他文字按连续的字母数字计为一个词；有内容时至少 1 分钟
+func ReadingStats(content string) (wordCount int, minutes int) {
+	content = markdownLinkTargetPattern.ReplaceAllString(content, "]")
+
+	cjk, words := 0, 0
+	inWord := false
+	for _, r := range content {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			cjk++
+			inWord = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if !inWord {
+				words++
+				inWord = true
+			}
+		default:
+			inWord = false
+		}
+	}
+
+	wordCount = cjk + words
+	if wordCount == 0 {
+		return 0, 0
+	}
+	minutes = int(math.Ceil(float64(cjk)/cjkCharsPerMinute + float64(words)/wordsPerMinute))
+	return wordCount, max(minutes, 1)
+}
@@ -0,0 +1,95 @@
+package common
+
+import (
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// 中文标题生成 slug 的方式，通过 ARTICLE_SLUG_CJK_MODE 配置
+const (
+	SlugCJKPinyin = "pinyin" // 转为拼音（默认）
+	SlugCJKKeep   = "keep"   // 保留中文字符
+	SlugCJKDrop   = "drop"   // 丢弃中文字符，由调用方兜底
+)
+
+const maxSlugLength = 80
+
+var pinyinArgs = pinyin.NewArgs()
+
+// Slugify 将标题转换为 URL 友好的 slug，只包含小写字母、数字和连字符；纯数字时加 post- 前缀
+func Slugify(title string) string {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("ARTICLE_SLUG_CJK_MODE")))
+	if mode == "" {
+		mode = SlugCJKPinyin
+	}
+
+	words := make([]string, 0, len(title))
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range title {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			switch mode {
+			case SlugCJKKeep:
+				words = append(words, string(r))
+			case SlugCJKDrop:
+			default:
+				if py := pinyin.SinglePinyin(r, pinyinArgs); len(py) > 0 {
+					words = append(words, py[0])
+				}
+			}
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			current.WriteRune(unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	slug := strings.Join(words, "-")
+	// 纯数字的 slug 会与 /post/<id> 的文章 ID 混淆
+	if isDigits(slug) {
+		slug = "post-" + slug
+	}
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(TruncateUTF8(slug, maxSlugLength), "-")
+	}
+	return slug
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// TruncateUTF8 按字节上限截断字符串，不会截断多字节字符
+func TruncateUTF8(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !isRuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
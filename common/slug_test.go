@@ -0,0 +1,26 @@
+package common
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	t.Setenv("ARTICLE_SLUG_CJK_MODE", "")
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{name: "english", title: "Hello, World!", want: "hello-world"},
+		{name: "pinyin", title: "Go 并发", want: "go-bing-fa"},
+		{name: "digits only", title: "2024", want: "post-2024"},
+		{name: "digits with separators", title: "1 234", want: "1-234"},
+		{name: "digits and letters", title: "2024 Review", want: "2024-review"},
+		{name: "empty", title: "!!!", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
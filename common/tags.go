@@ -0,0 +1,29 @@
+package common
+
+import "strings"
+
+// SplitTagNames 拆分逗号（含中文逗号）分隔的标签字符串，去除空白和重复项
+func SplitTagNames(raw string) []string {
+	fields := strings.FieldsFunc(raw, func(r rune) bool {
+		return r == ',' || r == '，'
+	})
+	return NormalizeTagNames(fields)
+}
+
+// NormalizeTagNames 去除标签的空白和重复项，保持原有顺序
+func NormalizeTagNames(names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		result = append(result, name)
+	}
+	return result
+}
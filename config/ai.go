@@ -0,0 +1,107 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// AIProviderOpenAI OpenAI 兼容接口（OpenRouter、llama.cpp、vLLM 等）
+	AIProviderOpenAI = "openai"
+	// AIProviderOllama Ollama 原生接口
+	AIProviderOllama = "ollama"
+	// AIProviderFake 确定性的假实现，不发起网络请求，用于测试和本地开发
+	AIProviderFake = "fake"
+
+	defaultChatBaseURL         = "https://llm.ooxo.cc/v1"
+	defaultChatModel           = "qwen2.5-1.5b-instruct"
+	defaultSummaryModel        = "google/gemini-2.5-flash"
+	defaultEmbeddingBaseURL    = "http://embed.ooxo.cc/v1"
+	defaultEmbeddingModel      = "text-embedding-v3"
+	defaultEmbeddingDimensions = 1024
+	defaultOllamaBaseURL       = "http://localhost:11434"
+)
+
+// ModelConfig 一个模型服务的连接配置
+type ModelConfig struct {
+	Provider string
+	BaseURL  string
+	APIKey   string
+	Model    string
+}
+
+// AIConfig 对话模型和向量模型的配置，摘要、查询扩展、回答、翻译分别使用各自的模型
+type AIConfig struct {
+	Summary             ModelConfig
+	QueryExpansion      ModelConfig
+	Answer              ModelConfig
+	Translation         ModelConfig
+	Embedding           ModelConfig
+	EmbeddingDimensions int
+}
+
+// LoadAIConfig 从环境变量读取模型配置
+//
+// LLM_PROVIDER / LLM_BASE_URL / LLM_API_KEY / LLM_MODEL 为对话模型的默认值，
+// 每个任务可以用 LLM_SUMMARY_*、LLM_QUERY_*、LLM_ANSWER_*、LLM_TRANSLATION_* 单独覆盖；
+// 向量模型使用 EMBEDDING_PROVIDER / EMBEDDING_BASE_URL / EMBEDDING_API_KEY / EMBEDDING_MODEL / EMBEDDING_DIMENSIONS
+func LoadAIConfig() AIConfig {
+	apiKey := os.Getenv("LLM_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("OPENROUTER_API_KEY")
+	}
+	chat := loadModelConfig("LLM", ModelConfig{
+		Provider: AIProviderOpenAI,
+		BaseURL:  defaultChatBaseURL,
+		APIKey:   apiKey,
+		Model:    defaultChatModel,
+	})
+
+	// 摘要默认沿用之前 Kafka 消费者中使用的模型
+	summary := chat
+	if os.Getenv("LLM_MODEL") == "" && chat.Provider == AIProviderOpenAI {
+		summary.Model = defaultSummaryModel
+	}
+
+	dimensions, err := strconv.Atoi(os.Getenv("EMBEDDING_DIMENSIONS"))
+	if err != nil || dimensions <= 0 {
+		dimensions = defaultEmbeddingDimensions
+	}
+	return AIConfig{
+		Summary:        loadModelConfig("LLM_SUMMARY", summary),
+		QueryExpansion: loadModelConfig("LLM_QUERY", chat),
+		Answer:         loadModelConfig("LLM_ANSWER", chat),
+		Translation:    loadModelConfig("LLM_TRANSLATION", summary), // 翻译需要较强的模型，默认与摘要相同
+		Embedding: loadModelConfig("EMBEDDING", ModelConfig{
+			Provider: AIProviderOpenAI,
+			BaseURL:  defaultEmbeddingBaseURL,
+			APIKey:   os.Getenv("EMBEDDING_API_KEY"),
+			Model:    defaultEmbeddingModel,
+		}),
+		EmbeddingDimensions: dimensions,
+	}
+}
+
+// loadModelConfig 读取 <prefix>_PROVIDER 等环境变量，未设置的项使用 fallback
+// 切换到 Ollama 且未指定地址时使用 Ollama 的默认地址
+func loadModelConfig(prefix string, fallback ModelConfig) ModelConfig {
+	cfg := fallback
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv(prefix + "_PROVIDER")))
+	if provider != "" && provider != fallback.Provider {
+		cfg.Provider = provider
+		if provider == AIProviderOllama {
+			cfg.BaseURL = defaultOllamaBaseURL
+		}
+	}
+	if baseURL := strings.TrimSpace(os.Getenv(prefix + "_BASE_URL")); baseURL != "" {
+		cfg.BaseURL = strings.TrimRight(baseURL, "/")
+	}
+	if apiKey := os.Getenv(prefix + "_API_KEY"); apiKey != "" {
+		cfg.APIKey = apiKey
+	}
+	if model := strings.TrimSpace(os.Getenv(prefix + "_MODEL")); model != "" {
+		cfg.Model = model
+	}
+	return cfg
+}
@@ -0,0 +1,21 @@
+-- 记录生成向量的模型和维度，不同模型的向量不能互相比较
+ALTER TABLE article ADD COLUMN IF NOT EXISTS embedding_model varchar(128) NOT NULL DEFAULT '';
+ALTER TABLE article ADD COLUMN IF NOT EXISTS embedding_dimensions integer NOT NULL DEFAULT 0;
+ALTER TABLE article_chunk ADD COLUMN IF NOT EXISTS embedding_model varchar(128) NOT NULL DEFAULT '';
+ALTER TABLE article_chunk ADD COLUMN IF NOT EXISTS embedding_dimensions integer NOT NULL DEFAULT 0;
+
+-- 已有的向量都由 text-embedding-v3 生成
+UPDATE article SET embedding_model = 'text-embedding-v3', embedding_dimensions = 1024
+  WHERE embedding IS NOT NULL AND embedding_model = '';
+UPDATE article_chunk SET embedding_model = 'text-embedding-v3', embedding_dimensions = 1024
+  WHERE embedding IS NOT NULL AND embedding_model = '';
+
+-- 去掉固定维度以便切换模型；HNSW 索引要求固定维度，改为按维度建立的表达式部分索引
+-- 切换到其他维度时，重新生成向量的任务会自动创建对应维度的索引
+DROP INDEX IF EXISTS article_chunk_embedding_idx;
+ALTER TABLE article ALTER COLUMN embedding TYPE vector;
+ALTER TABLE article_chunk ALTER COLUMN embedding TYPE vector;
+
+CREATE INDEX IF NOT EXISTS article_chunk_embedding_1024_idx
+  ON article_chunk USING hnsw ((embedding::vector(1024)) vector_cosine_ops)
+  WHERE embedding_dimensions = 1024;
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"blog-server-go/models"
+	"blog-server-go/services"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// VectorizeArticle 使用当前向量模型重新生成单篇文章的整篇向量和片段向量
+func (ah *ArticleHandler) VectorizeArticle(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var article models.Article
+	result := ah.DB.Take(&article, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Article not found"})
+		}
+		log.Errorf("Database error: %v", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve article"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	if err := services.EmbedArticle(ctx, ah.DB, ah.AI.Embedding, article); err != nil {
+		log.Errorf("Failed to embed article %s: %v", article.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate embedding"})
+	}
+	if _, err := services.SyncArticleChunks(ctx, ah.DB, ah.AI.Embedding, article); err != nil {
+		log.Errorf("Failed to sync chunks of article %s: %v", article.ID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate chunk embeddings"})
+	}
+	ah.Redis.Del(context.Background(), relatedCacheKey)
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"id":      id,
+		"version": services.ActiveEmbeddingVersion(ah.AI.Embedding),
+		"message": "Article vectorized successfully",
+	})
+}
+
+// StartEmbeddingJob 开始或继续在后台为所有文章重新生成向量
+// 请求体可选：ratePerMinute 每分钟的向量请求数，restart 为 true 时忽略上次的进度从头开始
+func (ah *ArticleHandler) StartEmbeddingJob(c *fiber.Ctx) error {
+	var req struct {
+		RatePerMinute int  `json:"ratePerMinute"`
+		Restart       bool `json:"restart"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+		}
+	}
+
+	progress, err := ah.EmbeddingJob.Start(req.RatePerMinute, req.Restart)
+	if errors.Is(err, services.ErrEmbeddingJobRunning) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Embedding job is already running"})
+	}
+	if err != nil {
+		log.Errorf("Failed to start embedding job: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to start embedding job"})
+	}
+	return c.Status(fiber.StatusAccepted).JSON(progress)
+}
+
+// GetEmbeddingJob 重新生成向量任务的进度
+func (ah *ArticleHandler) GetEmbeddingJob(c *fiber.Ctx) error {
+	progress, err := ah.EmbeddingJob.Progress(context.Background())
+	if err != nil {
+		log.Errorf("Failed to load embedding job: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	return c.JSON(progress)
+}
+
+// PauseEmbeddingJob 暂停任务，之后可以通过 StartEmbeddingJob 继续
+func (ah *ArticleHandler) PauseEmbeddingJob(c *fiber.Ctx) error {
+	progress, err := ah.EmbeddingJob.Pause()
+	if errors.Is(err, services.ErrEmbeddingJobNotRunning) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Embedding job is not running"})
+	}
+	if err != nil {
+		log.Errorf("Failed to pause embedding job: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	return c.JSON(progress)
+}
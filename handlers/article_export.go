@@ -0,0 +1,318 @@
+package handlers
+
+import (
+	"archive/zip"
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+// 静态站点导出目标
+const (
+	exportTargetHugo   = "hugo"
+	exportTargetHexo   = "hexo"
+	exportTargetJekyll = "jekyll"
+)
+
+// exportFilenameLimit 文件名（不含日期前缀和扩展名）的字节上限
+const exportFilenameLimit = 100
+
+// staticSiteLayout 各生成器的目录结构与默认图片目录
+type staticSiteLayout struct {
+	PostDir   string
+	DraftDir  string
+	ImageBase string
+}
+
+var staticSiteLayouts = map[string]staticSiteLayout{
+	exportTargetHugo:   {PostDir: "content/posts", DraftDir: "content/posts", ImageBase: "/images"},
+	exportTargetHexo:   {PostDir: "source/_posts", DraftDir: "source/_drafts", ImageBase: "/images"},
+	exportTargetJekyll: {PostDir: "_posts", DraftDir: "_drafts", ImageBase: "/assets/images"},
+}
+
+type hugoFrontMatter struct {
+	Title   string   `yaml:"title"`
+	Date    string   `yaml:"date"`
+	Lastmod string   `yaml:"lastmod"`
+	Slug    string   `yaml:"slug,omitempty"`
+	Tags    []string `yaml:"tags,omitempty,flow"`
+	Summary string   `yaml:"summary,omitempty"`
+	Draft   bool     `yaml:"draft"`
+}
+
+type hexoFrontMatter struct {
+	Title       string   `yaml:"title"`
+	Date        string   `yaml:"date"`
+	Updated     string   `yaml:"updated"`
+	Tags        []string `yaml:"tags,omitempty,flow"`
+	Description string   `yaml:"description,omitempty"`
+	Permalink   string   `yaml:"permalink,omitempty"`
+}
+
+type jekyllFrontMatter struct {
+	Layout    string   `yaml:"layout"`
+	Title     string   `yaml:"title"`
+	Date      string   `yaml:"date"`
+	Tags      []string `yaml:"tags,omitempty,flow"`
+	Excerpt   string   `yaml:"excerpt,omitempty"`
+	Permalink string   `yaml:"permalink,omitempty"`
+	Published *bool    `yaml:"published,omitempty"`
+}
+
+var (
+	markdownImagePattern = regexp.MustCompile(`(!\[[^\]]*\]\()(\S+?)((?:\s+"[^"]*")?\))`)
+	htmlImagePattern     = regexp.MustCompile(`(<img\b[^>]*?\bsrc=["'])([^"']+)(["'])`)
+	unsafeFilenameChars  = regexp.MustCompile(`[/\\:*?"<>|#%{}^~\[\]` + "`" + `]+`)
+	filenameDashes       = regexp.MustCompile(`-{2,}`)
+)
+
+// exportStaticSite 按 Hugo / Hexo / Jekyll 的目录结构和 front matter 导出全部文章，非管理员只导出已发布的文章
+// imageBase 为空时使用生成器的默认图片目录，OSS 上的图片会被改写到该目录下，并在 images.txt 中列出原地址
+func (ah *ArticleHandler) exportStaticSite(c *fiber.Ctx, target string, imageBase string) error {
+	layout, ok := staticSiteLayouts[target]
+	if !ok {
+		return &common.BusinessException{Code: 5000, Message: "Unsupported export target"}
+	}
+	if imageBase == "" {
+		imageBase = layout.ImageBase
+	}
+	imageBase = strings.TrimRight(imageBase, "/")
+
+	// 导出接口不需要登录，只有管理员可以导出草稿和定时发布的文章
+	query := ah.DB.Where("is_deleted", false)
+	if !isAdminRequest(c) {
+		query = publishedArticles(ah.DB)
+	}
+	var articles []models.Article
+	if err := query.Order("created_at").Find(&articles).Error; err != nil {
+		log.Error("Database error:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve articles"})
+	}
+	if len(articles) == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "No articles found"})
+	}
+
+	attachSummaries(ah.DB, articles)
+	imageHosts := exportImageHosts()
+	images := make(map[string]string)
+	usedNames := make(map[string]struct{})
+
+	var zipBuffer bytes.Buffer
+	zipWriter := zip.NewWriter(&zipBuffer)
+	for _, article := range articles {
+		date := article.CreatedAt
+		if article.PublishAt != nil {
+			date = *article.PublishAt
+		}
+		draft := article.Status != "" && article.Status != models.ArticleStatusPublished
+		content := rewriteImageURLs(article.Content, imageHosts, imageBase, images)
+		description := article.Summary
+		if article.MetaDescription != "" {
+			description = article.MetaDescription
+		}
+		frontMatter, err := staticSiteFrontMatter(target, article, date, description, draft)
+		if err != nil {
+			log.Error("Error encoding front matter:", err)
+			zipWriter.Close()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create zip file"})
+		}
+
+		dir := layout.PostDir
+		if draft {
+			dir = layout.DraftDir
+		}
+		name := sanitizeExportFilename(article.Slug)
+		if name == "" {
+			name = sanitizeExportFilename(article.Title)
+		}
+		if name == "" {
+			name = "post-" + string(article.ID)
+		}
+		// Jekyll 草稿不带日期前缀，其余文件统一使用 YYYY-MM-DD- 前缀
+		if !(target == exportTargetJekyll && draft) {
+			name = date.Format("2006-01-02") + "-" + name
+		}
+		filename := uniqueExportPath(usedNames, dir, name, ".md")
+
+		writer, err := zipWriter.Create(filename)
+		if err != nil {
+			log.Error("Error creating zip entry:", err)
+			zipWriter.Close()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create zip file"})
+		}
+		if _, err = writer.Write(append(frontMatter, content...)); err != nil {
+			log.Error("Error writing to zip:", err)
+			zipWriter.Close()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to write to zip file"})
+		}
+	}
+
+	if len(images) > 0 {
+		if err := writeImageManifest(zipWriter, images); err != nil {
+			log.Error("Error writing image manifest:", err)
+			zipWriter.Close()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to write to zip file"})
+		}
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		log.Error("Error closing zip writer:", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to close zip file"})
+	}
+
+	c.Response().Header.Set("Content-Type", "application/zip")
+	c.Response().Header.Set("Content-Disposition", fmt.Sprintf("attachment; filename*=UTF-8''%s-articles.zip", target))
+	return c.Send(zipBuffer.Bytes())
+}
+
+// staticSiteFrontMatter 生成目标生成器格式的 front matter
+func staticSiteFrontMatter(target string, article models.Article, date time.Time, summary string, draft bool) ([]byte, error) {
+	tags := parseTagNames(article.Tag)
+	var permalink string
+	if article.Slug != "" {
+		permalink = "/post/" + article.Slug + "/"
+	}
+
+	var fm interface{}
+	switch target {
+	case exportTargetHugo:
+		fm = hugoFrontMatter{
+			Title:   article.Title,
+			Date:    date.Format(time.RFC3339),
+			Lastmod: article.UpdatedAt.Format(time.RFC3339),
+			Slug:    article.Slug,
+			Tags:    tags,
+			Summary: summary,
+			Draft:   draft,
+		}
+	case exportTargetHexo:
+		fm = hexoFrontMatter{
+			Title:       article.Title,
+			Date:        date.Format("2006-01-02 15:04:05"),
+			Updated:     article.UpdatedAt.Format("2006-01-02 15:04:05"),
+			Tags:        tags,
+			Description: summary,
+			Permalink:   strings.TrimPrefix(permalink, "/"),
+		}
+	default:
+		jekyll := jekyllFrontMatter{
+			Layout:    "post",
+			Title:     article.Title,
+			Date:      date.Format("2006-01-02 15:04:05 -0700"),
+			Tags:      tags,
+			Excerpt:   summary,
+			Permalink: permalink,
+		}
+		if draft {
+			published := false
+			jekyll.Published = &published
+		}
+		fm = jekyll
+	}
+
+	return common.MarshalFrontMatter(fm)
+}
+
+// exportImageHosts 需要改写的图片地址前缀，即上传接口返回的 OSS 地址
+func exportImageHosts() []string {
+	endpoint := strings.TrimRight(os.Getenv("OSS_ENDPOINT"), "/")
+	if endpoint == "" {
+		return nil
+	}
+	hosts := []string{endpoint}
+	if rest, ok := strings.CutPrefix(endpoint, "https://"); ok {
+		hosts = append(hosts, "http://"+rest)
+	} else if rest, ok := strings.CutPrefix(endpoint, "http://"); ok {
+		hosts = append(hosts, "https://"+rest)
+	}
+	return hosts
+}
+
+// rewriteImageURLs 将 Markdown 和 HTML 图片中的 OSS 地址改写为 imageBase 下的相对路径，images 记录 新路径 -> 原地址
+func rewriteImageURLs(content string, hosts []string, imageBase string, images map[string]string) string {
+	if len(hosts) == 0 {
+		return content
+	}
+	rewrite := func(src string) string {
+		for _, host := range hosts {
+			if rest, ok := strings.CutPrefix(src, host+"/"); ok {
+				if i := strings.IndexAny(rest, "?#"); i >= 0 {
+					rest = rest[:i]
+				}
+				local := imageBase + "/" + rest
+				images[local] = src
+				return local
+			}
+		}
+		return src
+	}
+	content = markdownImagePattern.ReplaceAllStringFunc(content, func(match string) string {
+		parts := markdownImagePattern.FindStringSubmatch(match)
+		return parts[1] + rewrite(parts[2]) + parts[3]
+	})
+	return htmlImagePattern.ReplaceAllStringFunc(content, func(match string) string {
+		parts := htmlImagePattern.FindStringSubmatch(match)
+		return parts[1] + rewrite(parts[2]) + parts[3]
+	})
+}
+
+// writeImageManifest 写出图片清单，每行为 本地路径<TAB>原地址，便于离线下载
+func writeImageManifest(zipWriter *zip.Writer, images map[string]string) error {
+	locals := make([]string, 0, len(images))
+	for local := range images {
+		locals = append(locals, local)
+	}
+	sort.Strings(locals)
+
+	writer, err := zipWriter.Create("images.txt")
+	if err != nil {
+		return err
+	}
+	for _, local := range locals {
+		if _, err := fmt.Fprintf(writer, "%s\t%s\n", local, images[local]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sanitizeExportFilename 去掉路径分隔符等文件系统不允许的字符，空白替换为 -
+func sanitizeExportFilename(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		if unicode.IsSpace(r) {
+			return '-'
+		}
+		return r
+	}, name)
+	name = unsafeFilenameChars.ReplaceAllString(name, "-")
+	name = filenameDashes.ReplaceAllString(name, "-")
+	name = strings.Trim(name, "-. ")
+	return strings.TrimRight(common.TruncateUTF8(name, exportFilenameLimit), "-. ")
+}
+
+// uniqueExportPath 同一目录下文件名重复时追加 -2、-3，比较时忽略大小写以兼容不区分大小写的文件系统
+func uniqueExportPath(used map[string]struct{}, dir string, name string, ext string) string {
+	candidate := path.Join(dir, name+ext)
+	for n := 2; ; n++ {
+		key := strings.ToLower(candidate)
+		if _, ok := used[key]; !ok {
+			used[key] = struct{}{}
+			return candidate
+		}
+		candidate = path.Join(dir, fmt.Sprintf("%s-%d%s", name, n, ext))
+	}
+}
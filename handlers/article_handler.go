@@ -21,11 +21,9 @@ import (
 	"sync"
 	"time"
 
-	"github.com/cloudwego/eino/schema"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
 	"github.com/meilisearch/meilisearch-go"
-	"github.com/pgvector/pgvector-go"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
@@ -33,30 +31,70 @@ import (
 // ArticleHandler 处理与文章相关的请求
 type ArticleHandler struct {
 	BaseHandler
-	LLMService *services.LLMService
+	AI           *services.AIServices
+	EmbeddingJob *services.EmbeddingJob
 }
 
-const articleSearchIndex = "blog"
-
-type articleSearchDocument struct {
-	ID        string         `json:"id"`
-	Title     string         `json:"title"`
-	Content   string         `json:"content"`
-	Tag       string         `json:"tag"`
+// articleSearchHit 带高亮字段的搜索结果
+type articleSearchHit struct {
+	services.ArticleSearchDocument
 	Formatted map[string]any `json:"_formatted,omitempty"`
 }
 
+// articleSortFields 文章列表允许的排序字段
+var articleSortFields = map[string]string{
+	"created_at": "created_at",
+	"createdAt":  "created_at",
+	"updated_at": "updated_at",
+	"updatedAt":  "updated_at",
+	"view_count": "view_count",
+	"viewCount":  "view_count",
+}
+
+// parseArticleOrder 解析 order 参数，格式为 "字段 [asc|desc]"，只允许白名单字段
+func parseArticleOrder(orderStr string) (common.SortKey, error) {
+	parts := strings.Fields(orderStr)
+	if len(parts) == 0 || len(parts) > 2 {
+		return common.SortKey{}, common.NewBusinessException(5000, "Invalid order value")
+	}
+	column, ok := articleSortFields[parts[0]]
+	if !ok {
+		return common.SortKey{}, common.NewBusinessException(5000, "Unsupported order field")
+	}
+	key := common.SortKey{Column: column, Desc: true}
+	if len(parts) == 2 {
+		switch strings.ToLower(parts[1]) {
+		case "asc":
+			key.Desc = false
+		case "desc":
+		default:
+			return common.SortKey{}, common.NewBusinessException(5000, "Invalid order direction")
+		}
+	}
+	return key, nil
+}
+
+// parseDateParam 解析日期参数，支持 2006-01-02 与 RFC3339；dateOnly 表示只给了日期
+func parseDateParam(value string) (t time.Time, dateOnly bool, err error) {
+	if t, err = time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err = time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
 // GetArticles 获取所有文章
+// 带 cursor 参数时返回游标分页结构 {items, nextCursor, hasMore}，否则保持返回数组
 func (ah *ArticleHandler) GetArticles(c *fiber.Ctx) error {
 	var articles []models.Article
 
 	orderStr := c.Query("order", "created_at desc")
-	limitStr := c.Query("limit")
 	isActive := c.Query("isActive", "true")
+	status := c.Query("status")
 	tagStr := c.Query("tag")
 	isRss := c.Query("rss")
 	log.Info("isRss", isRss)
-	fields := "id,title,tag,created_at,updated_at,sort_order"
+	fields := "id,title,slug,tag,view_count,created_at,updated_at,sort_order,status,publish_at"
 	if isRss == "true" {
 		fields += ",CONTENT"
 	}
@@ -64,41 +102,91 @@ func (ah *ArticleHandler) GetArticles(c *fiber.Ctx) error {
 		fields += ",is_active"
 	}
 
-	query := ah.DB.Select(fields).Where("is_deleted", false).Where("is_active", isActive).Order("sort_order desc").Order(orderStr)
+	orderKey, err := parseArticleOrder(orderStr)
+	if err != nil {
+		return err
+	}
+	// 置顶优先，id 保证游标唯一
+	sortKeys := []common.SortKey{{Column: "sort_order", Desc: true}, orderKey, {Column: "id", Desc: orderKey.Desc}}
+	pageReq, err := common.ParsePageRequest(c, sortKeys, 20, 100)
+	if err != nil {
+		return err
+	}
+
+	query := ah.DB.Select(fields).Where("is_deleted", false)
+
+	// 管理员指定 status 时按状态过滤，否则 isActive=true 只返回已发布文章；其他人只能看到已发布文章
+	switch {
+	case !isAdminRequest(c):
+		query = query.Where("status = ?", models.ArticleStatusPublished)
+	case status != "":
+		query = query.Where("status = ?", status)
+	case isActive == "true":
+		query = query.Where("status = ?", models.ArticleStatusPublished)
+	default:
+		query = query.Where("status <> ?", models.ArticleStatusPublished)
+	}
 
 	if tagStr != "" {
-		query = query.Where("tag = ?", tagStr)
+		query = query.Where("id IN (?)", taggedArticleIDs(ah.DB, tagStr))
 	}
-	// 如果提供了 limit 参数，则应用它
-	if limitStr != "" {
-		limit, err := strconv.Atoi(limitStr)
+	// 按创建时间范围过滤，to 只给日期时包含当天
+	if from := c.Query("from"); from != "" {
+		fromTime, _, err := parseDateParam(from)
 		if err != nil {
-			return c.Status(fiber.StatusBadRequest).SendString("Invalid limit value")
+			return common.NewBusinessException(5000, "Invalid from value")
 		}
-		query = query.Limit(limit)
+		query = query.Where("created_at >= ?", fromTime)
 	}
-	result := query.Find(&articles)
+	if to := c.Query("to"); to != "" {
+		toTime, dateOnly, err := parseDateParam(to)
+		if err != nil {
+			return common.NewBusinessException(5000, "Invalid to value")
+		}
+		if dateOnly {
+			query = query.Where("created_at < ?", toTime.AddDate(0, 0, 1))
+		} else {
+			query = query.Where("created_at <= ?", toTime)
+		}
+	}
+
+	result := common.ApplyKeyset(query, sortKeys, pageReq).Find(&articles)
 	if result.Error != nil {
 		log.Error(result.Error)
 		return c.Status(fiber.StatusInternalServerError).SendString(result.Error.Error())
 	}
 
-	// 从Redis获取所有文章的summary
-	var ctx = context.Background()
-	allSummaries, _ := ah.Redis.HGetAll(ctx, "articleSummary").Result()
-
-	// 为每篇文章设置summary
+	attachSummaries(ah.DB, articles)
 	for i := range articles {
-		articles[i].Summary = allSummaries[string(articles[i].ID)]
+		articles[i].Tags = parseTagNames(articles[i].Tag)
 	}
 
-	return c.JSON(articles)
+	if !pageReq.Enabled {
+		return c.JSON(articles)
+	}
+	return c.JSON(common.NewPage(articles, sortKeys, pageReq, func(article models.Article) []string {
+		var value string
+		switch orderKey.Column {
+		case "updated_at":
+			value = article.UpdatedAt.Format(time.RFC3339Nano)
+		case "view_count":
+			value = strconv.Itoa(article.ViewCount)
+		default:
+			value = article.CreatedAt.Format(time.RFC3339Nano)
+		}
+		return []string{strconv.Itoa(article.SortOrder), value, string(article.ID)}
+	}))
 }
 
 func (ah *ArticleHandler) GetArticleByID(c *fiber.Ctx) error {
 	id := c.Params("id")
+	// 非管理员只能访问已发布的文章
+	visible := ah.DB.Where("is_deleted", false)
+	if !isAdminRequest(c) {
+		visible = publishedArticles(ah.DB)
+	}
 	var article models.Article
-	result := ah.DB.Take(&article, id)
+	result := visible.Take(&article, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
@@ -107,10 +195,19 @@ func (ah *ArticleHandler) GetArticleByID(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
 	}
 
-	// 从Redis获取summary
-	var ctx = context.Background()
-	summary, _ := ah.Redis.HGet(ctx, "articleSummary", id).Result()
-	article.Summary = summary
+	articles := []models.Article{article}
+	attachSummaries(ah.DB, articles)
+	article = articles[0]
+	article.Tags = parseTagNames(article.Tag)
+	// 请求其他语言时返回译文，没有译文时返回原文
+	applyTranslation(ah.DB, &article, c.Query("lang"))
+
+	// 所属系列及上一篇/下一篇
+	series, err := loadSeriesNavigation(ah.DB, article.ID)
+	if err != nil {
+		log.Errorf("Failed to load series navigation: %v", err)
+	}
+	article.Series = series
 
 	return c.JSON(article)
 }
@@ -145,10 +242,31 @@ func (ah *ArticleHandler) CreateArticle(c *fiber.Ctx) error {
 			Message: "无法解析JSON",
 		}
 	}
+	if err := resolveArticleStatus(&article); err != nil {
+		return err
+	}
+	normalizeArticleTags(&article)
+	if err := ensureArticleID(&article); err != nil {
+		log.Errorf("Failed to generate article id: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
 
-	result := ah.DB.Omit("embedding").Create(&article)
-	if result.Error != nil {
-		log.Errorf("Failed to save article: %v", result.Error) // 使用你的日志库记录错误
+	err := ah.DB.Transaction(func(tx *gorm.DB) error {
+		slug, err := resolveArticleSlug(tx, article.ID, article.Slug, article.Title)
+		if err != nil {
+			return err
+		}
+		article.Slug = slug
+		if err := tx.Omit("embedding").Create(&article).Error; err != nil {
+			return err
+		}
+		if err := syncArticleTags(tx, article.ID, article.Tags); err != nil {
+			return err
+		}
+		return recordArticleRevision(tx, nil, &article, models.RevisionSourceAdmin, editorFromCtx(c), 0)
+	})
+	if err != nil {
+		log.Errorf("Failed to save article: %v", err) // 使用你的日志库记录错误
 		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
 	}
 	ah.KafkaProducer.ProduceMessage(kafka.ArticleUpdateTopic, "id", string(article.ID))
@@ -166,10 +284,11 @@ func (ah *ArticleHandler) UpdateArticle(c *fiber.Ctx) error {
 			"error": "无法解析JSON",
 		})
 	}
+	normalizeArticleTags(&inputArticle)
 
+	// 回收站中的文章不能编辑，避免重新生成向量和片段
 	var existingArticle models.Article
-	ah.Redis.HDel(context.Background(), "articleSummary", id)
-	result := ah.DB.Take(&existingArticle, "id = ?", id)
+	result := ah.DB.Where("is_deleted", false).Take(&existingArticle, "id = ?", id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
@@ -177,68 +296,171 @@ func (ah *ArticleHandler) UpdateArticle(c *fiber.Ctx) error {
 		log.Errorf("Failed to retrieve article: %v", result.Error) // 使用你的日志库记录错误
 		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
 	}
+	// 编辑已发布的文章时保留原发布时间，避免列表、feed 和 sitemap 的顺序变化
+	if inputArticle.PublishAt == nil && existingArticle.Status == models.ArticleStatusPublished {
+		inputArticle.PublishAt = existingArticle.PublishAt
+	}
+	if err := resolveArticleStatus(&inputArticle); err != nil {
+		return err
+	}
 
-	// 更新文章内容
-	result = ah.DB.Model(&existingArticle).Updates(map[string]interface{}{
-		"title":     inputArticle.Title,
-		"content":   inputArticle.Content,
-		"tag":       inputArticle.Tag,
-		"is_active": inputArticle.IsActive,
+	// 更新文章内容，并保存修订记录
+	previousArticle := existingArticle
+	err := ah.DB.Transaction(func(tx *gorm.DB) error {
+		// 未指定 slug 时保持原有链接不变，旧文章缺失 slug 时补全
+		slug := existingArticle.Slug
+		if (inputArticle.Slug != "" && inputArticle.Slug != existingArticle.Slug) || slug == "" {
+			var err error
+			if slug, err = resolveArticleSlug(tx, existingArticle.ID, inputArticle.Slug, inputArticle.Title); err != nil {
+				return err
+			}
+			if err := saveArticleSlugRedirect(tx, existingArticle.ID, existingArticle.Slug, slug); err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&existingArticle).Updates(map[string]interface{}{
+			"title":            inputArticle.Title,
+			"slug":             slug,
+			"content":          inputArticle.Content,
+			"tag":              inputArticle.Tag,
+			"is_active":        inputArticle.IsActive,
+			"status":           inputArticle.Status,
+			"publish_at":       inputArticle.PublishAt,
+			"meta_description": inputArticle.MetaDescription,
+		}).Error; err != nil {
+			return err
+		}
+		existingArticle.Title = inputArticle.Title
+		existingArticle.Slug = slug
+		existingArticle.Content = inputArticle.Content
+		existingArticle.Tag = inputArticle.Tag
+		existingArticle.IsActive = inputArticle.IsActive
+		existingArticle.Status = inputArticle.Status
+		existingArticle.PublishAt = inputArticle.PublishAt
+		existingArticle.MetaDescription = inputArticle.MetaDescription
+		existingArticle.Tags = inputArticle.Tags
+		if err := syncArticleTags(tx, existingArticle.ID, inputArticle.Tags); err != nil {
+			return err
+		}
+		return recordArticleRevision(tx, &previousArticle, &existingArticle, models.RevisionSourceAdmin, editorFromCtx(c), 0)
 	})
 	ah.KafkaProducer.ProduceMessage(kafka.ArticleUpdateTopic, "id", id)
-	if result.Error != nil {
-		log.Errorf("Failed to update article: %v", result.Error) // 使用你的日志库记录错误
+	if err != nil {
+		log.Errorf("Failed to update article: %v", err) // 使用你的日志库记录错误
 		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
 	}
+	ah.revalidateOldSlug(previousArticle.Slug, existingArticle.Slug)
 
 	return c.JSON(existingArticle)
 }
 
+// SearchArticles 搜索文章，mode=hybrid 时融合关键词和语义搜索
+// 带 page、offset 或 facets 参数时返回 SearchPage 结构，否则保持返回数组
 func (ah *ArticleHandler) SearchArticles(c *fiber.Ctx) error {
-	keyword := strings.TrimSpace(c.Query("keyword"))
-	if keyword == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "keyword is required"})
+	query, paged, err := parseArticleSearchQuery(c)
+	if err != nil {
+		return err
 	}
 
 	ctx := context.Background()
-	_, err := ah.Redis.ZIncrBy(ctx, "searchKeywords", 1, keyword).Result()
-	common.HandleError(err, "Error incrementing keyword score:")
+	recordSearchKeyword(ctx, ah.Redis, query.Keyword)
+
+	var result *articleSearchResult
+	if c.Query("mode") == searchModeHybrid {
+		var mode string
+		result, mode, err = ah.hybridSearch(query)
+		if err == nil {
+			c.Set("X-Search-Mode", mode)
+		}
+	} else {
+		result, err = ah.keywordSearch(query)
+		if err != nil {
+			log.Errorf("Failed to search articles in Meilisearch: %v", err)
+		}
+	}
+	if err != nil {
+		var be *common.BusinessException
+		if errors.As(err, &be) {
+			return err
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Search failed"})
+	}
+	// 带过滤条件或翻页时结果为空不代表缺少内容
+	if !query.filtered() && query.Offset == 0 {
+		recordSearchResult(ctx, ah.Redis, query.Keyword, len(result.Hits))
+	}
+	if !paged {
+		return c.JSON(result.Hits)
+	}
+	return c.JSON(newSearchPage(query, result))
+}
 
-	if err := ah.ensureMeiliArticleIndex(); err != nil {
+// keywordSearch Meilisearch 关键词搜索，返回带高亮的已发布文章、总数和分面统计
+func (ah *ArticleHandler) keywordSearch(query articleSearchQuery) (*articleSearchResult, error) {
+	if err := services.PrepareArticleIndex(ah.Meili, ah.Redis); err != nil {
 		log.Errorf("Failed to prepare Meilisearch index before search: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Search index is unavailable"})
+		return nil, &common.BusinessException{Code: 5000, Message: "Search index is unavailable"}
 	}
 
-	searchResult, err := ah.Meili.Index(articleSearchIndex).Search(keyword, &meilisearch.SearchRequest{
-		Limit:                20,
-		AttributesToRetrieve: []string{"id", "title", "content", "tag"},
-		AttributesToSearchOn: []string{"title", "content", "tag"},
+	request := &meilisearch.SearchRequest{
+		Filter:               query.meiliFilter(),
+		Sort:                 query.Sort,
+		Facets:               query.Facets,
+		AttributesToRetrieve: []string{"id", "title", "content", "tag", "tags"},
+		AttributesToSearchOn: []string{"title", "content", "tags"},
 		AttributesToHighlight: []string{
 			"title",
 			"content",
 			"tag",
+			"tags",
 		},
 		HighlightPreTag:  "<em>",
 		HighlightPostTag: "</em>",
-	})
+	}
+	if query.Page > 0 {
+		request.Page, request.HitsPerPage = query.Page, query.Limit
+	} else {
+		request.Offset, request.Limit = query.Offset, query.Limit
+	}
+	searchResult, err := ah.Meili.Index(services.ArticleSearchIndex).Search(query.Keyword, request)
 	if err != nil {
-		log.Errorf("Failed to search articles in Meilisearch: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Search failed"})
+		return nil, err
 	}
 
-	articles := make([]articleSearchDocument, 0, len(searchResult.Hits))
+	articles := make([]articleSearchHit, 0, len(searchResult.Hits))
 	if err := searchResult.Hits.DecodeInto(&articles); err != nil {
 		log.Errorf("Failed to decode Meilisearch hits: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Search result decode failed"})
+		return nil, &common.BusinessException{Code: 5000, Message: "Search result decode failed"}
+	}
+
+	// 索引可能滞后于文章状态，过滤掉未发布的文章
+	ids := make([]string, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+	var publishedIDs []models.SnowflakeID
+	if len(ids) > 0 {
+		if err := publishedArticles(ah.DB.Model(&models.Article{})).Where("id IN ?", ids).Pluck("id", &publishedIDs).Error; err != nil {
+			log.Errorf("Failed to filter search hits by status: %v", err)
+			return nil, &common.BusinessException{Code: 5000, Message: "Search failed"}
+		}
+	}
+	published := make(map[string]bool, len(publishedIDs))
+	for _, id := range publishedIDs {
+		published[string(id)] = true
 	}
 
 	results := make([]map[string]any, 0, len(articles))
 	for _, article := range articles {
+		if !published[article.ID] {
+			continue
+		}
 		item := map[string]any{
 			"id":      article.ID,
 			"title":   article.Title,
 			"content": article.Content,
 			"tag":     article.Tag,
+			"tags":    article.Tags,
 		}
 		if article.Formatted != nil {
 			if value, ok := article.Formatted["title"]; ok {
@@ -250,164 +472,53 @@ func (ah *ArticleHandler) SearchArticles(c *fiber.Ctx) error {
 			if value, ok := article.Formatted["tag"]; ok {
 				item["tag"] = value
 			}
+			if value, ok := article.Formatted["tags"]; ok {
+				item["tags"] = value
+			}
 		}
 		results = append(results, item)
 	}
 
-	return c.JSON(results)
-}
-func (ah *ArticleHandler) SyncSQLToMeili(c *fiber.Ctx) error {
-	if err := ah.resetMeiliArticleIndex(); err != nil {
-		log.Errorf("Failed to reset Meilisearch index: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to reset search index"})
-	}
-
-	query := ah.DB.Where("is_deleted", false).Where("is_active", true)
-	var articles []models.Article
-
-	result := query.Find(&articles)
-	if result.Error != nil {
-		log.Errorf("Failed to query articles for Meilisearch sync: %v", result.Error)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to load articles"})
-	}
-
-	documents := make([]articleSearchDocument, 0, len(articles))
-	for _, article := range articles {
-		documents = append(documents, articleSearchDocument{
-			ID:      string(article.ID),
-			Title:   article.Title,
-			Content: article.Content,
-			Tag:     article.Tag,
-		})
-	}
-
-	primaryKey := "id"
-	taskInfo, err := ah.Meili.Index(articleSearchIndex).AddDocuments(documents, &meilisearch.DocumentOptions{
-		PrimaryKey: &primaryKey,
-	})
-	if err != nil {
-		log.Errorf("Failed to sync documents to Meilisearch: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to sync articles"})
-	}
-	if _, err := ah.Meili.WaitForTask(taskInfo.TaskUID, 0); err != nil {
-		log.Errorf("Failed waiting Meilisearch sync task: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Index task failed"})
+	result := &articleSearchResult{Hits: results, Total: searchResult.EstimatedTotalHits}
+	if query.Page > 0 {
+		result.Total = searchResult.TotalHits
 	}
-
-	return c.JSON(fiber.Map{
-		"index": articleSearchIndex,
-		"count": len(documents),
-	})
-}
-
-func (ah *ArticleHandler) ensureMeiliArticleIndex() error {
-	_, err := ah.Meili.GetIndex(articleSearchIndex)
-	if err != nil {
-		var meiliErr *meilisearch.Error
-		if !errors.As(err, &meiliErr) || meiliErr.StatusCode != http.StatusNotFound {
-			return err
-		}
-
-		taskInfo, createErr := ah.Meili.CreateIndex(&meilisearch.IndexConfig{
-			Uid:        articleSearchIndex,
-			PrimaryKey: "id",
-		})
-		if createErr != nil {
-			return createErr
-		}
-		if _, waitErr := ah.Meili.WaitForTask(taskInfo.TaskUID, 0); waitErr != nil {
-			return waitErr
+	if len(searchResult.FacetDistribution) > 0 {
+		if err := json.Unmarshal(searchResult.FacetDistribution, &result.Facets); err != nil {
+			log.Errorf("Failed to decode facet distribution: %v", err)
 		}
 	}
-
-	searchableAttributes := []string{"title", "content", "tag"}
-	taskInfo, err := ah.Meili.Index(articleSearchIndex).UpdateSearchableAttributes(&searchableAttributes)
-	if err != nil {
-		return err
+	if len(searchResult.FacetStats) > 0 {
+		if err := json.Unmarshal(searchResult.FacetStats, &result.FacetStats); err != nil {
+			log.Errorf("Failed to decode facet stats: %v", err)
+		}
 	}
-	_, err = ah.Meili.WaitForTask(taskInfo.TaskUID, 0)
-	return err
+	return result, nil
 }
 
-func (ah *ArticleHandler) resetMeiliArticleIndex() error {
-	taskInfo, err := ah.Meili.DeleteIndex(articleSearchIndex)
+// SyncSQLToMeili 全量重建搜索索引：在新索引中写入全部已发布文章后与线上索引交换
+// 日常的增量更新由 ArticleUpdateTopic 消费者完成
+func (ah *ArticleHandler) SyncSQLToMeili(c *fiber.Ctx) error {
+	count, err := services.ReindexArticles(ah.Meili, ah.DB, ah.Redis)
 	if err != nil {
-		var meiliErr *meilisearch.Error
-		if !errors.As(err, &meiliErr) || meiliErr.StatusCode != http.StatusNotFound {
-			return err
-		}
-	} else {
-		if _, waitErr := ah.Meili.WaitForTask(taskInfo.TaskUID, 0); waitErr != nil {
-			return waitErr
-		}
+		log.Errorf("Failed to reindex articles to Meilisearch: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to reindex articles"})
 	}
 
-	taskInfo, err = ah.Meili.CreateIndex(&meilisearch.IndexConfig{
-		Uid:        articleSearchIndex,
-		PrimaryKey: "id",
+	return c.JSON(fiber.Map{
+		"index": services.ArticleSearchIndex,
+		"count": count,
 	})
-	if err != nil {
-		return err
-	}
-	if _, err := ah.Meili.WaitForTask(taskInfo.TaskUID, 0); err != nil {
-		return err
-	}
-
-	return ah.ensureMeiliArticleIndex()
-}
-
-func (ah *ArticleHandler) GetArticleSummary(c *fiber.Ctx) error {
-	id := c.Params("id")
-	var ctx = context.Background()
-	summary, _ := ah.Redis.HGet(ctx, "articleSummary", id).Result()
-
-	// 如果 summary 为空，则调用 LLM 生成
-	if summary == "" {
-		var article models.Article
-		result := ah.DB.Take(&article, id)
-		if result.Error != nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Article not found"})
-		}
-
-		// 使用 Eino 生成摘要
-		messages := []*schema.Message{
-			schema.UserMessage(fmt.Sprintf("请为以下文章生成一段简洁的摘要，以平均阅读速度300字/分钟为基准，根据摘要字数计算预计阅读时间,将摘要与阅读时间合并为一段，格式为\"[摘要内容](预计阅读时间:X分钟)\",控制在100字以内:\n\n%s", article.Content)),
-		}
-
-		content, err := ah.LLMService.GenerateText(ctx, messages)
-		if err != nil {
-			log.Errorf("Failed to generate summary: %v", err)
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate summary"})
-		}
-
-		summary = content
-		// 保存到 Redis
-		if err := ah.Redis.HSet(ctx, "articleSummary", id, summary).Err(); err != nil {
-			log.Error("Failed to save summary to Redis:", err)
-		}
-	}
-
-	return c.JSON(summary)
 }
 
-func (ah *ArticleHandler) UpdateArticleSummary(c *fiber.Ctx) error {
-	// 解析入参 summary 存入redis
-	// 入参 {summary:"xxxxxx"}
-	id := c.Params("id")
-	var ctx = context.Background()
-
-	var inputArticle struct {
-		Summary string `json:"summary"`
-	}
-	if err := c.BodyParser(&inputArticle); err != nil {
-		common.HandleError(err, "Error incrementing keyword score:")
-
-		return c.Status(fiber.StatusBadRequest).JSON(common.NewResponse(fiber.StatusBadRequest, "Invalid request body", nil))
-	}
-	if err := ah.Redis.HSet(ctx, "articleSummary", id, inputArticle.Summary).Err(); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(common.NewResponse(fiber.StatusInternalServerError, "Failed to update article summary", nil))
+// GetSearchIndexConsistency 检查 Postgres 与搜索索引的差异
+func (ah *ArticleHandler) GetSearchIndexConsistency(c *fiber.Ctx) error {
+	report, err := services.CheckArticleIndex(ah.Meili, ah.DB)
+	if err != nil {
+		log.Errorf("Failed to check search index consistency: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to check search index"})
 	}
-	return c.JSON("Article summary updated successfully")
+	return c.JSON(report)
 }
 
 // ExportArticleMarkdown handles exporting article as markdown file
@@ -442,7 +553,11 @@ func (ah *ArticleHandler) ExportArticleMarkdown(c *fiber.Ctx) error {
 }
 
 // ExportAllArticlesMarkdown handles exporting all articles as a zip file
+// target=hugo|hexo|jekyll 时按对应静态站点生成器的格式导出
 func (ah *ArticleHandler) ExportAllArticlesMarkdown(c *fiber.Ctx) error {
+	if target := strings.ToLower(c.Query("target")); target != "" {
+		return ah.exportStaticSite(c, target, c.Query("imageBase"))
+	}
 	var articles []models.Article
 	result := ah.DB.Find(&articles)
 	if result.Error != nil {
@@ -475,8 +590,14 @@ func (ah *ArticleHandler) ExportAllArticlesMarkdown(c *fiber.Ctx) error {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create zip file"})
 		}
 
-		// 写入文章内容
-		_, err = writer.Write([]byte(article.Content))
+		// 写入 front matter 与文章内容，便于再次导入时按 id / slug 匹配
+		frontMatter, err := articleFrontMatter(article)
+		if err != nil {
+			log.Error("Error encoding front matter:", err)
+			zipWriter.Close()
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to create zip file"})
+		}
+		_, err = writer.Write(append(frontMatter, article.Content...))
 		if err != nil {
 			log.Error("Error writing to zip:", err)
 			zipWriter.Close()
@@ -802,201 +923,9 @@ func (ah *ArticleHandler) SyncAllToDify(c *fiber.Ctx) error {
 	})
 }
 
-// GenerateEmbedding 调用本地 llama.cpp embedding API 生成向量
+// GenerateEmbedding 调用配置的向量模型生成向量
 func (ah *ArticleHandler) GenerateEmbedding(text string) ([]float32, error) {
-	// 准备请求体
-	reqBody := map[string]interface{}{
-		"model": "text-embedding-v3",
-		"input": []string{text},
-	}
-
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	// 创建请求
-	req, err := http.NewRequest("POST", "http://embed.ooxo.cc/v1/embeddings", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// 设置请求头
-	req.Header.Set("Content-Type", "application/json")
-
-	// 设置超时
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
-	req = req.WithContext(ctx)
-
-	// 发送请求
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s", string(respBody))
-	}
-
-	// 解析响应
-	var result struct {
-		Data []struct {
-			Embedding []float32 `json:"embedding"`
-		} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if len(result.Data) == 0 {
-		return nil, fmt.Errorf("no embedding returned")
-	}
-
-	return result.Data[0].Embedding, nil
-}
-
-// VectorizeArticle 对单篇文章进行向量化
-func (ah *ArticleHandler) VectorizeArticle(c *fiber.Ctx) error {
-	id := c.Params("id")
-	var article models.Article
-	result := ah.DB.Take(&article, id)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Article not found"})
-		}
-		log.Errorf("Database error: %v", result.Error)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to retrieve article"})
-	}
-
-	// 合并标题和内容/摘要生成向量
-	text := article.Title
-	if article.Content != "" {
-		text = fmt.Sprintf("标题：%s\n内容：%s", article.Title, article.Content)
-	} else if article.Summary != "" {
-		text = fmt.Sprintf("标题：%s\n摘要：%s", article.Title, article.Summary)
-	}
-	embedding, err := ah.GenerateEmbedding(text)
-	if err != nil {
-		log.Errorf("Failed to generate embedding for article %s: %v", article.ID, err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": fmt.Sprintf("Failed to generate embedding: %v", err)})
-	}
-
-	// 保存向量到数据库 - 使用原生 SQL 更新
-	vectorStr := pgvector.NewVector(embedding).String()
-	log.Infof("Vector string: %s", vectorStr)
-	if err := ah.DB.Exec("UPDATE article SET embedding = ? WHERE id = ?", vectorStr, article.ID).Error; err != nil {
-		log.Errorf("Failed to save embedding: %v", err)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to save embedding"})
-	}
-
-	return c.JSON(fiber.Map{
-		"success": true,
-		"id":      id,
-		"message": "Article vectorized successfully",
-	})
-}
-
-// VectorizeAllArticles 批量向量化所有文章
-func (ah *ArticleHandler) VectorizeAllArticles(c *fiber.Ctx) error {
-	// 获取所有文章
-	var articles []models.Article
-	query := ah.DB.Where("is_deleted", false).Where("is_active", true)
-	result := query.Find(&articles)
-	if result.Error != nil {
-		log.Errorf("Failed to fetch articles: %v", result.Error)
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to fetch articles",
-		})
-	}
-
-	if len(articles) == 0 {
-		return c.JSON(fiber.Map{
-			"total":   0,
-			"success": 0,
-			"failure": 0,
-			"message": "No articles to vectorize",
-		})
-	}
-
-	// 创建结果通道
-	type vectorizeResult struct {
-		ID      models.SnowflakeID `json:"id"`
-		Title   string             `json:"title"`
-		Success bool               `json:"success"`
-		Error   string             `json:"error,omitempty"`
-	}
-	results := make(chan vectorizeResult, len(articles))
-
-	// 创建一个通道来控制并发数量
-	semaphore := make(chan struct{}, 3) // 最多3个并发，避免API限流
-	var wg sync.WaitGroup
-
-	// 遍历所有文章进行向量化
-	for _, article := range articles {
-		wg.Add(1)
-		go func(article models.Article) {
-			defer wg.Done()
-
-			// 获取信号量
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			// 合并标题和内容/摘要生成向量
-			text := article.Title
-			if article.Content != "" {
-				text = fmt.Sprintf("标题：%s\n内容：%s", article.Title, article.Content)
-			} else if article.Summary != "" {
-				text = fmt.Sprintf("标题：%s\n摘要：%s", article.Title, article.Summary)
-			}
-			embedding, err := ah.GenerateEmbedding(text)
-			if err != nil {
-				results <- vectorizeResult{ID: article.ID, Title: article.Title, Success: false, Error: err.Error()}
-				return
-			}
-
-			// 保存向量到数据库 - 使用原生 SQL 更新
-			vectorStr := pgvector.NewVector(embedding).String()
-			if err := ah.DB.Exec("UPDATE article SET embedding = ? WHERE id = ?", vectorStr, article.ID).Error; err != nil {
-				results <- vectorizeResult{ID: article.ID, Title: article.Title, Success: false, Error: "Failed to save embedding"}
-				return
-			}
-
-			results <- vectorizeResult{ID: article.ID, Title: article.Title, Success: true}
-		}(article)
-	}
-
-	// 等待所有向量化完成
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// 收集结果
-	var vectorizeResults []vectorizeResult
-	for result := range results {
-		vectorizeResults = append(vectorizeResults, result)
-	}
-
-	// 统计结果
-	successCount := 0
-	failureCount := 0
-	for _, result := range vectorizeResults {
-		if result.Success {
-			successCount++
-		} else {
-			failureCount++
-		}
-	}
-
-	return c.JSON(fiber.Map{
-		"total":   len(articles),
-		"success": successCount,
-		"failure": failureCount,
-		"results": vectorizeResults,
-	})
+	return services.EmbedText(ctx, ah.AI.Embedding, text)
 }
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"archive/zip"
+	"blog-server-go/common"
+	"blog-server-go/kafka"
+	"blog-server-go/models"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	importActionCreate = "create"
+	importActionUpdate = "update"
+	importActionSkip   = "skip"
+	importActionError  = "error"
+
+	// importMaxFileSize 单个 Markdown 文件的大小上限
+	importMaxFileSize = 5 * 1024 * 1024
+	importMaxFiles    = 2000
+)
+
+// ArticleImportItem 单个文件的导入结果
+type ArticleImportItem struct {
+	File   string             `json:"file"`
+	Action string             `json:"action"`
+	ID     models.SnowflakeID `json:"id,omitempty"`
+	Title  string             `json:"title,omitempty"`
+	Slug   string             `json:"slug,omitempty"`
+	Error  string             `json:"error,omitempty"`
+}
+
+// ArticleImportResult 导入汇总
+type ArticleImportResult struct {
+	DryRun  bool                `json:"dryRun"`
+	Created int                 `json:"created"`
+	Updated int                 `json:"updated"`
+	Skipped int                 `json:"skipped"`
+	Failed  int                 `json:"failed"`
+	Items   []ArticleImportItem `json:"items"`
+}
+
+// ImportArticlesMarkdown 从 Markdown zip 导入文章，按 front matter 中的 id 或 slug 匹配已有文章
+// dryRun=true 时只返回将要执行的操作，不写入数据库
+func (ah *ArticleHandler) ImportArticlesMarkdown(c *fiber.Ctx) error {
+	dryRun := c.Query("dryRun") == "true"
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "file is required"})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Errorf("Failed to open uploaded zip: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to open file"})
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		log.Errorf("Failed to read uploaded zip: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to read file"})
+	}
+	zipReader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid zip file"})
+	}
+
+	// 先统计文件数，超出上限时在写入任何文章之前拒绝
+	var entries []*zip.File
+	for _, entry := range zipReader.File {
+		name := entry.Name
+		base := path.Base(name)
+		if entry.FileInfo().IsDir() || strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(base, ".") ||
+			!strings.EqualFold(path.Ext(base), ".md") {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if len(entries) > importMaxFiles {
+		return &common.BusinessException{Code: 5000, Message: "Too many files in zip"}
+	}
+
+	result := ArticleImportResult{DryRun: dryRun, Items: []ArticleImportItem{}}
+	editor := editorFromCtx(c)
+	changed := make([]models.SnowflakeID, 0)
+	for _, entry := range entries {
+		item := ah.importMarkdownEntry(entry, dryRun, editor)
+		switch item.Action {
+		case importActionCreate:
+			result.Created++
+		case importActionUpdate:
+			result.Updated++
+		case importActionSkip:
+			result.Skipped++
+		default:
+			result.Failed++
+		}
+		if !dryRun && (item.Action == importActionCreate || item.Action == importActionUpdate) {
+			changed = append(changed, item.ID)
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	for _, id := range changed {
+		ah.KafkaProducer.ProduceMessage(kafka.ArticleUpdateTopic, "id", string(id))
+	}
+	return c.JSON(result)
+}
+
+// importMarkdownEntry 解析并导入 zip 中的单个 Markdown 文件
+func (ah *ArticleHandler) importMarkdownEntry(entry *zip.File, dryRun bool, editor revisionEditor) ArticleImportItem {
+	item := ArticleImportItem{File: entry.Name}
+	fail := func(err error) ArticleImportItem {
+		item.Action = importActionError
+		item.Error = err.Error()
+		return item
+	}
+
+	if entry.UncompressedSize64 > importMaxFileSize {
+		return fail(errors.New("file too large"))
+	}
+	reader, err := entry.Open()
+	if err != nil {
+		return fail(err)
+	}
+	raw, err := io.ReadAll(io.LimitReader(reader, importMaxFileSize+1))
+	reader.Close()
+	if err != nil {
+		return fail(err)
+	}
+	// zip 中记录的大小可能与实际内容不符，超出上限时不导入截断的内容
+	if len(raw) > importMaxFileSize {
+		return fail(errors.New("file too large"))
+	}
+
+	fm, content, err := common.ParseFrontMatter(raw)
+	if err != nil {
+		return fail(err)
+	}
+	if fm.Title == "" {
+		fm.Title = strings.TrimSuffix(path.Base(entry.Name), path.Ext(entry.Name))
+	}
+	date, err := fm.ParseDate()
+	if err != nil {
+		return fail(err)
+	}
+	status := fm.Status
+	if status == "" && fm.Draft != nil {
+		status = models.ArticleStatusPublished
+		if *fm.Draft {
+			status = models.ArticleStatusDraft
+		}
+	}
+
+	incoming := models.Article{Title: fm.Title, Content: content, Tags: fm.Tags, Slug: fm.Slug, Status: status}
+	normalizeArticleTags(&incoming)
+	item.Title = incoming.Title
+
+	existing, err := ah.findImportTarget(fm)
+	if err != nil {
+		return fail(err)
+	}
+
+	if existing == nil {
+		item.Action = importActionCreate
+		item.Slug = fm.Slug
+		if dryRun {
+			return item
+		}
+		// 新导入的文章未指定状态时保存为草稿，避免批量导入直接公开
+		if incoming.Status == "" {
+			incoming.Status = models.ArticleStatusDraft
+		}
+		if incoming.Status == models.ArticleStatusPublished || incoming.Status == models.ArticleStatusScheduled {
+			incoming.PublishAt = date
+		}
+		if err := resolveArticleStatus(&incoming); err != nil {
+			return fail(err)
+		}
+		if err := ensureArticleID(&incoming); err != nil {
+			return fail(err)
+		}
+		if date != nil {
+			incoming.CreatedAt = *date
+		}
+		err = ah.DB.Transaction(func(tx *gorm.DB) error {
+			slug, err := resolveArticleSlug(tx, incoming.ID, incoming.Slug, incoming.Title)
+			if err != nil {
+				return err
+			}
+			incoming.Slug = slug
+			if err := tx.Omit("embedding").Create(&incoming).Error; err != nil {
+				return err
+			}
+			if err := syncArticleTags(tx, incoming.ID, incoming.Tags); err != nil {
+				return err
+			}
+			return recordArticleRevision(tx, nil, &incoming, models.RevisionSourceImport, editor, 0)
+		})
+		if err != nil {
+			return fail(err)
+		}
+		item.ID, item.Slug = incoming.ID, incoming.Slug
+		return item
+	}
+
+	item.ID, item.Slug = existing.ID, existing.Slug
+	slugChanged := fm.Slug != "" && common.Slugify(fm.Slug) != existing.Slug
+	statusChanged := incoming.Status != "" && incoming.Status != existing.Status
+	if existing.Title == incoming.Title && existing.Content == incoming.Content && existing.Tag == incoming.Tag &&
+		!slugChanged && !statusChanged {
+		item.Action = importActionSkip
+		return item
+	}
+	item.Action = importActionUpdate
+	if dryRun {
+		return item
+	}
+
+	previous := *existing
+	updates := map[string]interface{}{
+		"title":   incoming.Title,
+		"content": incoming.Content,
+		"tag":     incoming.Tag,
+	}
+	if statusChanged {
+		incoming.PublishAt = existing.PublishAt
+		if incoming.Status == models.ArticleStatusScheduled && date != nil {
+			incoming.PublishAt = date
+		}
+		if err := resolveArticleStatus(&incoming); err != nil {
+			return fail(err)
+		}
+		updates["status"] = incoming.Status
+		updates["is_active"] = incoming.IsActive
+		updates["publish_at"] = incoming.PublishAt
+	}
+	err = ah.DB.Transaction(func(tx *gorm.DB) error {
+		if slugChanged {
+			slug, err := resolveArticleSlug(tx, existing.ID, fm.Slug, incoming.Title)
+			if err != nil {
+				return err
+			}
+			if err := saveArticleSlugRedirect(tx, existing.ID, existing.Slug, slug); err != nil {
+				return err
+			}
+			updates["slug"] = slug
+			existing.Slug = slug
+		}
+		if err := tx.Model(existing).Updates(updates).Error; err != nil {
+			return err
+		}
+		existing.Title, existing.Content, existing.Tag = incoming.Title, incoming.Content, incoming.Tag
+		if err := syncArticleTags(tx, existing.ID, incoming.Tags); err != nil {
+			return err
+		}
+		return recordArticleRevision(tx, &previous, existing, models.RevisionSourceImport, editor, 0)
+	})
+	if err != nil {
+		return fail(err)
+	}
+	ah.revalidateOldSlug(previous.Slug, existing.Slug)
+	item.Slug = existing.Slug
+	return item
+}
+
+// findImportTarget 按 id、slug（含历史 slug）查找要更新的文章，未找到返回 nil；回收站中的文章不会被覆盖
+// 指定了 id 但找不到对应文章时返回错误，避免把其他站点导出的文件当作新文章导入
+func (ah *ArticleHandler) findImportTarget(fm common.FrontMatter) (*models.Article, error) {
+	var article models.Article
+	if fm.ID != "" {
+		result := ah.DB.Where("id = ? AND is_deleted = ?", fm.ID, false).Limit(1).Find(&article)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, fmt.Errorf("article %s not found", fm.ID)
+		}
+		return &article, nil
+	}
+	if fm.Slug == "" {
+		return nil, nil
+	}
+	slug := common.Slugify(fm.Slug)
+	result := ah.DB.Where("slug = ? AND is_deleted = ?", slug, false).Limit(1).Find(&article)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return &article, nil
+	}
+	var redirect models.ArticleSlugRedirect
+	result = ah.DB.Where("old_slug = ?", slug).Limit(1).Find(&redirect)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	result = ah.DB.Where("id = ? AND is_deleted = ?", redirect.ArticleID, false).Limit(1).Find(&article)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+	return &article, nil
+}
+
+// articleFrontMatter 导出时写入的 front matter，导入时据此匹配文章
+func articleFrontMatter(article models.Article) ([]byte, error) {
+	return common.MarshalFrontMatter(common.FrontMatter{
+		ID:     string(article.ID),
+		Title:  article.Title,
+		Slug:   article.Slug,
+		Date:   article.CreatedAt.Format("2006-01-02 15:04:05"),
+		Tags:   parseTagNames(article.Tag),
+		Status: article.Status,
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"blog-server-go/models"
+	"blog-server-go/services"
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// metadataVocabularyLimit 提供给模型的已有标签数量上限，按文章数取最常用的
+const metadataVocabularyLimit = 300
+
+// SuggestArticleMetadata 根据草稿生成标签、SEO 描述、备选标题和 slug 建议，不修改文章
+// 入参 {id, title, content, tags}：带 id 且未传标题或正文时使用已保存的文章
+func (ah *ArticleHandler) SuggestArticleMetadata(c *fiber.Ctx) error {
+	var req struct {
+		ID models.SnowflakeID `json:"id"`
+		services.MetadataDraft
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+
+	if req.ID != "" && (req.Title == "" || req.Content == "") {
+		var article models.Article
+		if err := ah.DB.Select("id,title,content,tag").Where("is_deleted", false).Take(&article, "id = ?", req.ID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Article not found"})
+			}
+			log.Errorf("Failed to retrieve article: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+		}
+		if req.Title == "" {
+			req.Title = article.Title
+		}
+		if req.Content == "" {
+			req.Content = article.Content
+		}
+		if len(req.Tags) == 0 {
+			req.Tags = parseTagNames(article.Tag)
+		}
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" && strings.TrimSpace(req.Content) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Title or content is required"})
+	}
+	req.Tags = normalizeTagNames(req.Tags)
+
+	tags, err := queryTagCounts(ah.DB)
+	if err != nil {
+		log.Errorf("Failed to load tags: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	vocabulary := make([]string, 0, min(len(tags), metadataVocabularyLimit))
+	for _, tag := range tags[:min(len(tags), metadataVocabularyLimit)] {
+		vocabulary = append(vocabulary, tag.Name)
+	}
+
+	suggestion, err := services.SuggestArticleMetadata(context.Background(), ah.AI.Summary, req.MetadataDraft, vocabulary)
+	if errors.Is(err, services.ErrNoMetadataSuggestion) {
+		log.Errorf("Failed to parse metadata suggestion: %v", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": "Model returned no suggestion"})
+	}
+	if err != nil {
+		log.Errorf("Failed to suggest article metadata: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to suggest metadata"})
+	}
+
+	// 建议的 slug 与其他文章冲突时追加序号，新文章没有 ID，用 0 代替
+	articleID := req.ID
+	if articleID == "" {
+		articleID = "0"
+	}
+	suggestion.Slug, err = resolveArticleSlug(ah.DB, articleID, suggestion.Slug, req.Title)
+	if err != nil {
+		log.Errorf("Failed to resolve suggested slug: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	return c.JSON(suggestion)
+}
@@ -1,14 +1,19 @@
 package handlers
 
 import (
+	"blog-server-go/common"
 	"blog-server-go/models"
+	"blog-server-go/services"
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
@@ -21,7 +26,8 @@ const expandQueryPrompt = `你是一个搜索查询扩展助手。用户会给
 要求：
 1. 生成 2-4 个搜索关键词/短语
 2. 包含原始问题的核心词、同义词、相关概念
-3. 只返回 JSON 数组，不要其他内容
+3. 如果有对话历史，追问中省略的主题要结合历史补全，例如上一轮在聊 Node 20 的新特性，追问“那 Node 22 呢”应扩展为 Node 22 新特性相关的关键词
+4. 只返回 JSON 数组，不要其他内容
 
 示例：
 用户问题：Go 怎么处理错误
@@ -32,8 +38,9 @@ const expandQueryPrompt = `你是一个搜索查询扩展助手。用户会给
 
 // RAGQuestionRequest RAG 问答请求
 type RAGQuestionRequest struct {
-	Question string `json:"question"`
-	TopK     int    `json:"topK,omitempty"` // 每个关键词返回前 K 篇相关文章
+	Question       string `json:"question"`
+	TopK           int    `json:"topK,omitempty"`           // 每个关键词返回前 K 篇相关文章
+	ConversationID string `json:"conversationId,omitempty"` // 为空时创建新会话
 }
 
 // ArticleWithSimilarity 带相似度的文章
@@ -42,44 +49,211 @@ type ArticleWithSimilarity struct {
 	Similarity float32 `json:"similarity"`
 }
 
-// searchArticlesByVector 向量搜索文章
-func (ah *ArticleHandler) searchArticlesByVector(query string, topK int) ([]ArticleWithSimilarity, error) {
-	if topK <= 0 {
-		topK = 3
+// ChunkWithSimilarity 带相似度的文章片段
+type ChunkWithSimilarity struct {
+	ID          models.SnowflakeID `json:"id"`
+	ArticleID   models.SnowflakeID `json:"articleId"`
+	Title       string             `json:"title"`
+	Slug        string             `json:"slug"`
+	HeadingPath string             `json:"headingPath"`
+	Content     string             `json:"content"`
+	TokenCount  int                `json:"tokenCount"`
+	Similarity  float32            `json:"similarity"`
+}
+
+const (
+	// ragChunksPerQuery 每个扩展关键词召回的片段数为 topK 的倍数
+	ragChunksPerQuery = 3
+	// ragContextTokenBudget 拼接到 prompt 中的参考内容 token 上限
+	ragContextTokenBudget = 6000
+	// ragArticleContentLimit 回退到整篇文章检索时每篇文章的字节上限
+	ragArticleContentLimit = 2000
+)
+
+// searchChunksByVector 按片段向量搜索，只返回已发布文章中由当前向量模型生成的片段
+func (ah *ArticleHandler) searchChunksByVector(queryEmbedding []float32, limit int) ([]ChunkWithSimilarity, error) {
+	var results []ChunkWithSimilarity
+	version := services.ActiveEmbeddingVersion(ah.AI.Embedding)
+	column := version.VectorColumn("chunk.embedding")
+	sqlQuery := fmt.Sprintf(`
+		SELECT chunk.id, chunk.article_id, article.title, article.slug, chunk.heading_path,
+		       chunk.content, chunk.token_count,
+		       1 - (%[1]s <=> ?) as similarity
+		FROM article_chunk chunk
+		JOIN article ON article.id = chunk.article_id
+		WHERE article.is_deleted = false AND article.status = 'published' AND chunk.embedding IS NOT NULL
+		  AND chunk.embedding_model = ? AND chunk.embedding_dimensions = %[2]d
+		ORDER BY %[1]s <=> ? ASC
+		LIMIT ?
+	`, column, version.Dimensions)
+	vector := pgvector.NewVector(queryEmbedding)
+	if err := ah.DB.Raw(sqlQuery, vector, version.Model, vector, limit).Scan(&results).Error; err != nil {
+		return nil, fmt.Errorf("failed to search chunks: %w", err)
+	}
+	return results, nil
+}
+
+// ragSource 参考来源，按文章聚合命中的片段
+type ragSource struct {
+	ID           models.SnowflakeID `json:"id"`
+	Title        string             `json:"title"`
+	Slug         string             `json:"slug"`
+	Summary      string             `json:"summary"`
+	Similarity   float32            `json:"similarity"`
+	HeadingPaths []string           `json:"headingPaths"`
+}
+
+// retrieveRAGContext 用所有扩展关键词检索片段，按相似度在 token 预算内拼接参考内容
+// 每个片段带有编号，回答中以 [n] 引用；尚未切分片段时回退到整篇文章检索
+func (ah *ArticleHandler) retrieveRAGContext(queries []string, topK int) (string, []ragSource, ragPassages) {
+	chunks := make(map[models.SnowflakeID]ChunkWithSimilarity)
+	articleFallback := make(map[string]ArticleWithSimilarity)
+	for _, query := range queries {
+		queryEmbedding, err := ah.GenerateEmbedding(query)
+		if err != nil {
+			log.Errorf("生成查询向量失败 (query=%s): %v", query, err)
+			continue
+		}
+		results, err := ah.searchChunksByVector(queryEmbedding, topK*ragChunksPerQuery)
+		if err != nil {
+			log.Errorf("片段搜索失败 (query=%s): %v", query, err)
+			continue
+		}
+		if len(results) == 0 {
+			articles, err := ah.searchArticlesByEmbedding(queryEmbedding, topK)
+			if err != nil {
+				log.Errorf("搜索失败 (query=%s): %v", query, err)
+				continue
+			}
+			for _, article := range articles {
+				key := string(article.ID)
+				if existing, exists := articleFallback[key]; !exists || article.Similarity > existing.Similarity {
+					articleFallback[key] = article
+				}
+			}
+			continue
+		}
+		for _, chunk := range results {
+			// 保留相似度最高的结果
+			if existing, exists := chunks[chunk.ID]; !exists || chunk.Similarity > existing.Similarity {
+				chunks[chunk.ID] = chunk
+			}
+		}
+	}
+
+	ranked := make([]ChunkWithSimilarity, 0, len(chunks))
+	for _, chunk := range chunks {
+		ranked = append(ranked, chunk)
+	}
+	sort.Slice(ranked, func(i, j int) bool { return ranked[i].Similarity > ranked[j].Similarity })
+	for _, article := range articleFallback {
+		ranked = append(ranked, ChunkWithSimilarity{
+			ArticleID:  article.ID,
+			Title:      article.Title,
+			Slug:       article.Slug,
+			Content:    common.TruncateUTF8(article.Content, ragArticleContentLimit),
+			Similarity: article.Similarity,
+		})
+	}
+
+	var contextBuilder strings.Builder
+	var sources []ragSource
+	var passages ragPassages
+	sourceIndex := make(map[models.SnowflakeID]int)
+	budget := ragContextTokenBudget
+	for _, chunk := range ranked {
+		tokens := chunk.TokenCount
+		if tokens == 0 {
+			tokens = common.EstimateTokens(chunk.Content)
+		}
+		if tokens > budget {
+			continue
+		}
+		budget -= tokens
+
+		number := passages.add(ragPassage{
+			ArticleID:   chunk.ArticleID,
+			Title:       chunk.Title,
+			Slug:        chunk.Slug,
+			HeadingPath: chunk.HeadingPath,
+			Content:     chunk.Content,
+		})
+		if chunk.HeadingPath != "" {
+			contextBuilder.WriteString(fmt.Sprintf("[%d]【%s%s%s】\n", number, chunk.Title, common.HeadingPathSeparator, chunk.HeadingPath))
+		} else {
+			contextBuilder.WriteString(fmt.Sprintf("[%d]【%s】\n", number, chunk.Title))
+		}
+		contextBuilder.WriteString(chunk.Content)
+		contextBuilder.WriteString("\n\n")
+
+		index, ok := sourceIndex[chunk.ArticleID]
+		if !ok {
+			index = len(sources)
+			sourceIndex[chunk.ArticleID] = index
+			sources = append(sources, ragSource{ID: chunk.ArticleID, Title: chunk.Title, Slug: chunk.Slug, Similarity: chunk.Similarity, HeadingPaths: []string{}})
+		}
+		if chunk.HeadingPath != "" {
+			sources[index].HeadingPaths = append(sources[index].HeadingPaths, chunk.HeadingPath)
+		}
+	}
+
+	if len(sources) > 0 {
+		ids := make([]models.SnowflakeID, 0, len(sources))
+		for _, source := range sources {
+			ids = append(ids, source.ID)
+		}
+		summaries := shortSummaries(ah.DB, ids)
+		for i := range sources {
+			sources[i].Summary = summaries[sources[i].ID]
+		}
 	}
+	return contextBuilder.String(), sources, passages
+}
 
+// searchArticlesByVector 向量搜索文章
+func (ah *ArticleHandler) searchArticlesByVector(query string, topK int) ([]ArticleWithSimilarity, error) {
 	// 生成查询向量
 	queryEmbedding, err := ah.GenerateEmbedding(query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate embedding: %w", err)
 	}
+	return ah.searchArticlesByEmbedding(queryEmbedding, topK)
+}
+
+// searchArticlesByEmbedding 按整篇文章的向量搜索
+func (ah *ArticleHandler) searchArticlesByEmbedding(queryEmbedding []float32, topK int) ([]ArticleWithSimilarity, error) {
+	if topK <= 0 {
+		topK = 3
+	}
 
-	// 向量相似度搜索
+	// 向量相似度搜索，只比较当前向量模型生成的向量
 	var results []ArticleWithSimilarity
+	version := services.ActiveEmbeddingVersion(ah.AI.Embedding)
 	sqlQuery := `
 		SELECT id, created_at, updated_at, is_deleted, created_by, updated_by,
-		       title, content, view_count, tag, sort_order, is_active,
+		       title, slug, content, view_count, tag, sort_order, is_active,
 		       1 - (embedding <=> ?) as similarity
 		FROM article
-		WHERE is_deleted = false AND is_active = true AND embedding IS NOT NULL
+		WHERE is_deleted = false AND status = 'published' AND embedding IS NOT NULL
+		  AND embedding_model = ? AND embedding_dimensions = ?
 		ORDER BY embedding <=> ? ASC
 		LIMIT ?
 	`
-	if err := ah.DB.Raw(sqlQuery, pgvector.NewVector(queryEmbedding), pgvector.NewVector(queryEmbedding), topK).Scan(&results).Error; err != nil {
+	vector := pgvector.NewVector(queryEmbedding)
+	if err := ah.DB.Raw(sqlQuery, vector, version.Model, version.Dimensions, vector, topK).Scan(&results).Error; err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
 
 	return results, nil
 }
 
-// expandQuery 使用 LLM 扩展搜索关键词
-func (ah *ArticleHandler) expandQuery(ctx context.Context, question string) ([]string, error) {
-	messages := []*schema.Message{
-		schema.SystemMessage(expandQueryPrompt),
-		schema.UserMessage(question),
-	}
+// expandQuery 使用 LLM 扩展搜索关键词，history 为之前的对话，用于补全追问中省略的主题
+func (ah *ArticleHandler) expandQuery(ctx context.Context, question string, history []*schema.Message) ([]string, error) {
+	messages := []*schema.Message{schema.SystemMessage(expandQueryPrompt)}
+	messages = append(messages, history...)
+	messages = append(messages, schema.UserMessage(question))
 
-	content, err := ah.LLMService.GenerateText(ctx, messages)
+	content, err := ah.AI.QueryExpansion.GenerateText(ctx, messages)
 	if err != nil {
 		return nil, fmt.Errorf("failed to expand query: %w", err)
 	}
@@ -95,7 +269,32 @@ func (ah *ArticleHandler) expandQuery(ctx context.Context, question string) ([]s
 	return queries, nil
 }
 
-// RAGQuestion RAG 问答接口（查询扩展 + 向量搜索 + 流式回答）
+// retrievalRAGMessages 查询扩展 + 片段检索，将编号的参考内容拼接到 system prompt 中
+func (ah *ArticleHandler) retrievalRAGMessages(question string, history []*schema.Message, topK int) ([]*schema.Message, []ragSource, ragPassages) {
+	expandCtx, expandCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer expandCancel()
+
+	queries, err := ah.expandQuery(expandCtx, question, history)
+	if err != nil {
+		log.Errorf("查询扩展失败，回退使用原始问题: %v", err)
+		queries = []string{question}
+	}
+	log.Infof("查询扩展结果: %v", queries)
+
+	// 用所有关键词检索片段，合并去重后在 token 预算内拼接参考内容
+	ragContext, sources, passages := ah.retrieveRAGContext(queries, topK)
+	messages := []*schema.Message{
+		schema.SystemMessage("你是博客助手。请根据以下参考文章内容回答用户的问题。如果参考内容中没有相关信息，请如实告知。" + ragCitationInstruction + "\n\n" + ragContext),
+	}
+	messages = append(messages, history...)
+	messages = append(messages, schema.UserMessage(question))
+	return messages, sources, passages
+}
+
+// RAGQuestion RAG 问答接口（工具调用检索 + 流式回答）
+// SSE 事件依次为 conversation、tool_call / tool_result、articles、message、citations、done
+// 回答中的 [n] 为引用编号，citations 给出每个编号对应的文章和原文，不存在的编号在输出前即被去掉
+// 同一 conversationId 下的提问会带上之前的对话；topK 只在回退到查询扩展检索时生效
 func (ah *ArticleHandler) RAGQuestion(c *fiber.Ctx) error {
 	var req RAGQuestionRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -111,45 +310,30 @@ func (ah *ArticleHandler) RAGQuestion(c *fiber.Ctx) error {
 		topK = 3
 	}
 
-	// 第一步：LLM 扩展搜索关键词
-	expandCtx, expandCancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer expandCancel()
-
-	queries, err := ah.expandQuery(expandCtx, req.Question)
-	if err != nil {
-		log.Errorf("查询扩展失败，回退使用原始问题: %v", err)
-		queries = []string{req.Question}
-	}
-	log.Infof("查询扩展结果: %v", queries)
-
-	// 第二步：用所有关键词批量向量搜索，合并去重
-	allArticles := make(map[string]ArticleWithSimilarity)
-	for _, query := range queries {
-		results, err := ah.searchArticlesByVector(query, topK)
+	visitorID := ragVisitorID(c, true)
+	var conversation *RAGConversation
+	if req.ConversationID != "" {
+		var err error
+		conversation, err = loadRAGConversation(context.Background(), ah.Redis, visitorID, req.ConversationID)
+		if errors.Is(err, errRAGConversationNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Conversation not found"})
+		}
 		if err != nil {
-			log.Errorf("搜索失败 (query=%s): %v", query, err)
-			continue
+			log.Errorf("Failed to load RAG conversation: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
 		}
-		for _, article := range results {
-			key := string(article.ID)
-			// 保留相似度最高的结果
-			if existing, exists := allArticles[key]; !exists || article.Similarity > existing.Similarity {
-				allArticles[key] = article
-			}
+	} else {
+		now := time.Now()
+		conversation = &RAGConversation{
+			ID:        newRAGConversationID(),
+			VisitorID: visitorID,
+			Title:     common.TruncateUTF8(req.Question, ragConversationTitleLimit),
+			Messages:  []RAGMessage{},
+			CreatedAt: now,
+			UpdatedAt: now,
 		}
 	}
-
-	// 构建搜索结果上下文
-	var contextBuilder strings.Builder
-	for _, article := range allArticles {
-		contextBuilder.WriteString(fmt.Sprintf("【%s】\n", article.Title))
-		if len(article.Content) < 2000 {
-			contextBuilder.WriteString(article.Content)
-		} else {
-			contextBuilder.WriteString(article.Content[:2000] + "...")
-		}
-		contextBuilder.WriteString("\n\n")
-	}
+	history := conversation.historyMessages()
 
 	// 设置 SSE 响应头
 	c.Set("Content-Type", "text/event-stream")
@@ -158,45 +342,57 @@ func (ah *ArticleHandler) RAGQuestion(c *fiber.Ctx) error {
 	c.Set("X-Accel-Buffering", "no")
 
 	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
-		// 发送搜索到的文章列表
-		if len(allArticles) > 0 {
-			articlesArray := make([]map[string]interface{}, 0, len(allArticles))
-			for _, article := range allArticles {
-				articlesArray = append(articlesArray, map[string]interface{}{
-					"id":         article.ID,
-					"title":      article.Title,
-					"summary":    article.Summary,
-					"similarity": article.Similarity,
-				})
-			}
-			articlesJSON, _ := json.Marshal(articlesArray)
-			fmt.Fprintf(w, "event: articles\ndata: %s\n\n", articlesJSON)
+		send := func(event string, payload any) {
+			data, _ := json.Marshal(payload)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
 			w.Flush()
 		}
+		send("conversation", map[string]string{"conversationId": conversation.ID})
+
+		// 第一步：模型通过工具调用检索文章，每次调用和结果都作为事件发送；模型不支持工具调用时回退到查询扩展检索
+		messages, sources, passages, err := ah.runRAGAgent(req.Question, history, send)
+		var opts []model.Option
+		if err != nil {
+			log.Warnf("工具调用失败，回退到查询扩展检索: %v", err)
+			messages, sources, passages = ah.retrievalRAGMessages(req.Question, history, topK)
+		} else {
+			opts = ragAgentAnswerOptions()
+		}
 
-		// 第三步：基于搜索结果流式生成回答
-		messages := []*schema.Message{
-			schema.SystemMessage("你是博客助手。请根据以下参考文章内容回答用户的问题。如果参考内容中没有相关信息，请如实告知。\n\n" + contextBuilder.String()),
-			schema.UserMessage(req.Question),
+		// 发送参考文章列表，包含命中片段的标题路径
+		if len(sources) > 0 {
+			send("articles", sources)
 		}
 
+		// 第二步：基于检索结果流式生成回答
 		streamCtx, streamCancel := context.WithTimeout(context.Background(), 120*time.Second)
 		defer streamCancel()
 
-		err := ah.LLMService.GenerateStream(streamCtx, messages, func(chunk string) error {
-			contentJSON, _ := json.Marshal(map[string]string{"content": chunk})
+		citations := newCitationFilter(passages)
+		writeContent := func(content string) error {
+			if content == "" {
+				return nil
+			}
+			contentJSON, _ := json.Marshal(map[string]string{"content": content})
 			fmt.Fprintf(w, "event: message\ndata: %s\n\n", contentJSON)
 			return w.Flush()
-		})
+		}
+		err = ah.AI.Answer.GenerateStream(streamCtx, messages, func(chunk string) error {
+			return writeContent(citations.Write(chunk))
+		}, opts...)
 
 		if err != nil {
 			log.Errorf("流式生成失败: %v", err)
-			errorJSON, _ := json.Marshal(map[string]string{"error": "Failed to stream response"})
-			fmt.Fprintf(w, "event: error\ndata: %s\n\n", errorJSON)
-			w.Flush()
+			send("error", map[string]string{"error": "Failed to stream response"})
 			return
 		}
 
+		writeContent(citations.Flush())
+		send("citations", citations.Citations())
+
+		// 只保存完整的回答，失败的一轮不进入历史
+		appendRAGTurn(ah.Redis, conversation, req.Question, citations.Text(), sources, citations.Citations())
+
 		fmt.Fprintf(w, "event: done\ndata: {}\n\n")
 		w.Flush()
 	})
@@ -0,0 +1,362 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	// ragAgentMaxIterations 工具调用的最大轮数，用完后基于已获得的信息直接回答
+	ragAgentMaxIterations = 4
+	// ragAgentStepTimeout 每轮工具调用决策的超时时间
+	ragAgentStepTimeout = 30 * time.Second
+	ragAgentSearchLimit = 5
+	// ragAgentSnippetLimit search_articles 返回片段的字节上限
+	ragAgentSnippetLimit = 600
+	// ragAgentArticleLimit get_article 返回正文的字节上限
+	ragAgentArticleLimit = 8000
+)
+
+// ragAgentPrompt 工具调用模式的 system prompt
+const ragAgentPrompt = `你是博客助手，可以调用工具查询博客文章来回答用户的问题。
+
+可用工具：
+- search_articles：按关键词和语义搜索文章，返回标题和最相关的片段
+- get_article：按文章 ID 获取全文，片段不足以回答时使用
+- list_tags：列出博客的所有标签及文章数，用于了解博客涵盖的主题
+
+要求：
+1. 回答博客相关的问题前先搜索，不要凭空编造博客内容
+2. 追问时结合对话历史确定要搜索的内容
+3. 信息足够时直接回答，不要重复相同的调用
+4. 如果博客中没有相关内容，请如实告知
+5. 工具返回的每段内容都有 ref 编号，回答时用 [ref] 标注依据，例如 [1] 或 [1][3]`
+
+// ragAgentAnswerPrompt 工具调用结束后生成最终回答的提示
+const ragAgentAnswerPrompt = "请根据以上工具返回的信息回答用户的问题，不要再调用工具。如果信息中没有相关内容，请如实告知。" + ragCitationInstruction
+
+var ragAgentTools = []*schema.ToolInfo{
+	{
+		Name: "search_articles",
+		Desc: "搜索博客文章，返回最相关的文章 ID、标题、所在章节和内容片段",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"query": {Type: schema.String, Desc: "搜索关键词或问题", Required: true},
+			"mode": {
+				Type: schema.String,
+				Desc: "搜索方式：hybrid 同时使用关键词和语义（默认），keyword 只按关键词，vector 只按语义",
+				Enum: []string{searchModeHybrid, searchModeKeyword, searchModeVector},
+			},
+		}),
+	},
+	{
+		Name: "get_article",
+		Desc: "按文章 ID 获取已发布文章的全文",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"id": {Type: schema.String, Desc: "search_articles 返回的文章 ID", Required: true},
+		}),
+	},
+	{
+		Name: "list_tags",
+		Desc: "列出博客的所有标签及每个标签下的文章数",
+	},
+}
+
+// ragToolCallEvent tool_call 事件：模型请求调用工具
+type ragToolCallEvent struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+	Iteration int    `json:"iteration"`
+}
+
+// ragToolResultEvent tool_result 事件：工具执行结果的摘要，完整结果只提供给模型
+type ragToolResultEvent struct {
+	ID       string      `json:"id"`
+	Name     string      `json:"name"`
+	Count    int         `json:"count"`
+	Articles []ragSource `json:"articles,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// ragAgent 一次问答中的工具调用状态，工具返回过的文章作为参考来源，返回过的内容编号后供回答引用
+type ragAgent struct {
+	handler     *ArticleHandler
+	sources     []ragSource
+	sourceIndex map[models.SnowflakeID]int
+	passages    ragPassages
+}
+
+// runRAGAgent 让模型循环调用工具检索信息，最多 ragAgentMaxIterations 轮
+// 返回用于生成最终回答的消息、参考来源和编号的参考内容；第一轮就无法调用模型时返回错误，由调用方回退到普通检索
+func (ah *ArticleHandler) runRAGAgent(question string, history []*schema.Message, send func(event string, payload any)) ([]*schema.Message, []ragSource, ragPassages, error) {
+	agent := &ragAgent{handler: ah, sourceIndex: make(map[models.SnowflakeID]int)}
+	messages := []*schema.Message{schema.SystemMessage(ragAgentPrompt)}
+	messages = append(messages, history...)
+	messages = append(messages, schema.UserMessage(question))
+
+	for iteration := 1; iteration <= ragAgentMaxIterations; iteration++ {
+		ctx, cancel := context.WithTimeout(context.Background(), ragAgentStepTimeout)
+		resp, err := ah.AI.Answer.GenerateWithTools(ctx, messages, ragAgentTools)
+		cancel()
+		if err != nil {
+			if iteration == 1 {
+				return nil, nil, nil, err
+			}
+			log.Errorf("工具调用中断，基于已有信息回答: %v", err)
+			break
+		}
+		if len(resp.ToolCalls) == 0 {
+			break
+		}
+
+		messages = append(messages, resp)
+		for _, call := range resp.ToolCalls {
+			send("tool_call", ragToolCallEvent{ID: call.ID, Name: call.Function.Name, Arguments: call.Function.Arguments, Iteration: iteration})
+			result, event := agent.execute(call)
+			send("tool_result", event)
+			content, _ := json.Marshal(result)
+			messages = append(messages, schema.ToolMessage(string(content), call.ID, schema.WithToolName(call.Function.Name)))
+		}
+	}
+
+	messages = append(messages, schema.UserMessage(ragAgentAnswerPrompt))
+	agent.fillSummaries()
+	return messages, agent.sources, agent.passages, nil
+}
+
+// ragAgentAnswerOptions 最终回答禁止再调用工具；历史消息中包含工具调用，仍需声明工具
+func ragAgentAnswerOptions() []model.Option {
+	return []model.Option{model.WithTools(ragAgentTools), model.WithToolChoice(schema.ToolChoiceForbidden)}
+}
+
+// execute 执行一次工具调用，返回给模型的结果和 SSE 事件
+func (agent *ragAgent) execute(call schema.ToolCall) (any, ragToolResultEvent) {
+	event := ragToolResultEvent{ID: call.ID, Name: call.Function.Name}
+	var result any
+	var err error
+	switch call.Function.Name {
+	case "search_articles":
+		result, err = agent.searchArticles(call.Function.Arguments, &event)
+	case "get_article":
+		result, err = agent.getArticle(call.Function.Arguments, &event)
+	case "list_tags":
+		result, err = agent.listTags(&event)
+	default:
+		err = fmt.Errorf("unknown tool %q", call.Function.Name)
+	}
+	if err != nil {
+		log.Warnf("工具 %s 调用失败 (arguments=%s): %v", call.Function.Name, call.Function.Arguments, err)
+		event.Error = err.Error()
+		return map[string]string{"error": err.Error()}, event
+	}
+	return result, event
+}
+
+func (agent *ragAgent) searchArticles(arguments string, event *ragToolResultEvent) (any, error) {
+	var args struct {
+		Query string `json:"query"`
+		Mode  string `json:"mode"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil || strings.TrimSpace(args.Query) == "" {
+		return nil, errors.New("query is required")
+	}
+	query := articleSearchQuery{Keyword: strings.TrimSpace(args.Query), Limit: ragAgentSearchLimit}
+
+	var hits []map[string]any
+	switch args.Mode {
+	case searchModeKeyword:
+		result, err := agent.handler.keywordSearch(query)
+		if err != nil {
+			return nil, err
+		}
+		hits = result.Hits
+	case searchModeVector:
+		var err error
+		if hits, err = agent.handler.vectorSearch(query, ragAgentSearchLimit); err != nil {
+			return nil, err
+		}
+	default:
+		result, _, err := agent.handler.hybridSearch(query)
+		if err != nil {
+			return nil, err
+		}
+		hits = result.Hits
+	}
+
+	// 关键词结果不含 slug，统一补齐
+	ids := make([]string, 0, len(hits))
+	for _, hit := range hits {
+		id, _ := hit["id"].(string)
+		ids = append(ids, id)
+	}
+	slugs := make(map[string]string, len(ids))
+	if len(ids) > 0 {
+		var articles []models.Article
+		if err := agent.handler.DB.Select("id,slug").Where("id IN ?", ids).Find(&articles).Error; err != nil {
+			return nil, err
+		}
+		for _, article := range articles {
+			slugs[string(article.ID)] = article.Slug
+		}
+	}
+
+	stripHighlight := strings.NewReplacer("<em>", "", "</em>", "")
+	type searchResult struct {
+		Ref         int    `json:"ref"`
+		ID          string `json:"id"`
+		Title       string `json:"title"`
+		Tag         string `json:"tag,omitempty"`
+		HeadingPath string `json:"headingPath,omitempty"`
+		Snippet     string `json:"snippet"`
+	}
+	results := make([]searchResult, 0, len(hits))
+	for _, hit := range hits {
+		id, _ := hit["id"].(string)
+		title, _ := hit["title"].(string)
+		content, _ := hit["content"].(string)
+		tag, _ := hit["tag"].(string)
+		headingPath, _ := hit["headingPath"].(string)
+		similarity, _ := hit["similarity"].(float32)
+		title = stripHighlight.Replace(title)
+		snippet := common.TruncateUTF8(stripHighlight.Replace(content), ragAgentSnippetLimit)
+		ref := agent.passages.add(ragPassage{
+			ArticleID:   models.SnowflakeID(id),
+			Title:       title,
+			Slug:        slugs[id],
+			HeadingPath: headingPath,
+			Content:     snippet,
+		})
+		results = append(results, searchResult{
+			Ref:         ref,
+			ID:          id,
+			Title:       title,
+			Tag:         stripHighlight.Replace(tag),
+			HeadingPath: headingPath,
+			Snippet:     snippet,
+		})
+		event.Articles = append(event.Articles, agent.addSource(ragSource{
+			ID:           models.SnowflakeID(id),
+			Title:        title,
+			Slug:         slugs[id],
+			Similarity:   similarity,
+			HeadingPaths: nonEmpty(headingPath),
+		}))
+	}
+	event.Count = len(results)
+	return results, nil
+}
+
+func (agent *ragAgent) getArticle(arguments string, event *ragToolResultEvent) (any, error) {
+	var args struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil || strings.TrimSpace(args.ID) == "" {
+		return nil, errors.New("id is required")
+	}
+	id := strings.TrimSpace(args.ID)
+
+	// 模型偶尔会传 slug，非数字时按 slug 查找
+	query := publishedArticles(agent.handler.DB.Select("id,title,slug,tag,content,created_at"))
+	if strings.Trim(id, "0123456789") == "" {
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("slug = ?", id)
+	}
+	var article models.Article
+	if err := query.Take(&article).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("article %s not found", id)
+		}
+		return nil, err
+	}
+
+	event.Count = 1
+	event.Articles = append(event.Articles, agent.addSource(ragSource{ID: article.ID, Title: article.Title, Slug: article.Slug}))
+	content := common.TruncateUTF8(article.Content, ragAgentArticleLimit)
+	ref := agent.passages.add(ragPassage{ArticleID: article.ID, Title: article.Title, Slug: article.Slug, Content: content})
+	return map[string]any{
+		"ref":       ref,
+		"id":        string(article.ID),
+		"title":     article.Title,
+		"tags":      parseTagNames(article.Tag),
+		"createdAt": article.CreatedAt.Format("2006-01-02"),
+		"content":   content,
+	}, nil
+}
+
+func (agent *ragAgent) listTags(event *ragToolResultEvent) (any, error) {
+	tags, err := queryTagCounts(agent.handler.DB)
+	if err != nil {
+		return nil, err
+	}
+	type tagResult struct {
+		Name         string `json:"name"`
+		ArticleCount int64  `json:"articleCount"`
+	}
+	results := make([]tagResult, 0, len(tags))
+	for _, tag := range tags {
+		if tag.ArticleCount > 0 {
+			results = append(results, tagResult{Name: tag.Name, ArticleCount: tag.ArticleCount})
+		}
+	}
+	event.Count = len(results)
+	return results, nil
+}
+
+// addSource 记录参考来源，同一篇文章合并命中的章节并保留最高相似度
+func (agent *ragAgent) addSource(source ragSource) ragSource {
+	if source.HeadingPaths == nil {
+		source.HeadingPaths = []string{}
+	}
+	index, ok := agent.sourceIndex[source.ID]
+	if !ok {
+		agent.sourceIndex[source.ID] = len(agent.sources)
+		agent.sources = append(agent.sources, source)
+		return source
+	}
+	existing := &agent.sources[index]
+	existing.Similarity = max(existing.Similarity, source.Similarity)
+	for _, path := range source.HeadingPaths {
+		if !slices.Contains(existing.HeadingPaths, path) {
+			existing.HeadingPaths = append(existing.HeadingPaths, path)
+		}
+	}
+	if existing.Slug == "" {
+		existing.Slug = source.Slug
+	}
+	return source
+}
+
+// fillSummaries 补充参考来源的摘要
+func (agent *ragAgent) fillSummaries() {
+	if len(agent.sources) == 0 {
+		return
+	}
+	ids := make([]models.SnowflakeID, 0, len(agent.sources))
+	for _, source := range agent.sources {
+		ids = append(ids, source.ID)
+	}
+	summaries := shortSummaries(agent.handler.DB, ids)
+	for i := range agent.sources {
+		agent.sources[i].Summary = summaries[agent.sources[i].ID]
+	}
+}
+
+func nonEmpty(value string) []string {
+	if value == "" {
+		return []string{}
+	}
+	return []string{value}
+}
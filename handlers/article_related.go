@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"blog-server-go/services"
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// relatedCacheKey 相关文章缓存的 Redis hash，字段为 "<id>:<limit>"
+// 任意文章重新向量化后都可能出现在其他文章的结果中，因此 kafka.ArticleHandler 会整体删除
+const relatedCacheKey = "articleRelated"
+
+const (
+	relatedDefaultLimit = 5
+	relatedMaxLimit     = 20
+	// relatedCandidateFactor 向量召回的候选数量为 limit 的倍数，再按标签加权重排
+	relatedCandidateFactor = 4
+	// relatedTagBoost 每个共同标签增加的分数，最多计 relatedMaxSharedTags 个
+	relatedTagBoost      = 0.05
+	relatedMaxSharedTags = 3
+	relatedCacheTTL      = 24 * time.Hour
+)
+
+// RelatedArticle 相关文章
+type RelatedArticle struct {
+	ID         models.SnowflakeID `json:"id"`
+	Title      string             `json:"title"`
+	Slug       string             `json:"slug"`
+	Tag        string             `json:"-"`
+	Tags       []string           `json:"tags" gorm:"-"`
+	Summary    string             `json:"summary" gorm:"-"`
+	PublishAt  *time.Time         `json:"publishAt"`
+	CreatedAt  time.Time          `json:"createdAt"`
+	Similarity float64            `json:"similarity"`
+	SharedTags int                `json:"sharedTags"`
+	Score      float64            `json:"score"`
+}
+
+// GetRelatedArticles 基于向量相似度并按共同标签加权，返回相似的已发布文章
+// 文章尚未向量化时只按共同标签推荐
+func (ah *ArticleHandler) GetRelatedArticles(c *fiber.Ctx) error {
+	id := c.Params("id")
+	limit := relatedDefaultLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		value, err := strconv.Atoi(limitStr)
+		if err != nil || value <= 0 {
+			return &common.BusinessException{Code: 5000, Message: "Invalid limit value"}
+		}
+		limit = min(value, relatedMaxLimit)
+	}
+
+	ctx := context.Background()
+	cacheField := id + ":" + strconv.Itoa(limit)
+	var related []RelatedArticle
+	if raw, err := ah.Redis.HGet(ctx, relatedCacheKey, cacheField).Result(); err != nil || json.Unmarshal([]byte(raw), &related) != nil {
+		var source models.Article
+		if err := ah.DB.Select("id").Where("is_deleted", false).Take(&source, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+			}
+			log.Errorf("Failed to retrieve article: %v", err)
+			return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+		}
+
+		related, err = ah.queryRelatedArticles(source.ID, limit)
+		if err != nil {
+			log.Errorf("Failed to query related articles: %v", err)
+			return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+		}
+		if data, err := json.Marshal(related); err == nil {
+			ah.Redis.HSet(ctx, relatedCacheKey, cacheField, data)
+			ah.Redis.Expire(ctx, relatedCacheKey, relatedCacheTTL)
+		}
+	}
+
+	// 摘要单独更新，不随相关文章缓存
+	if len(related) > 0 {
+		ids := make([]models.SnowflakeID, 0, len(related))
+		for _, article := range related {
+			ids = append(ids, article.ID)
+		}
+		summaries := shortSummaries(ah.DB, ids)
+		for i := range related {
+			related[i].Summary = summaries[related[i].ID]
+		}
+	}
+	return c.JSON(related)
+}
+
+// queryRelatedArticles 先按向量召回候选，再加上共同标签的分数重排；只比较当前向量模型生成的向量
+func (ah *ArticleHandler) queryRelatedArticles(articleID models.SnowflakeID, limit int) ([]RelatedArticle, error) {
+	var related []RelatedArticle
+	version := services.ActiveEmbeddingVersion(ah.AI.Embedding)
+	sqlQuery := `
+		WITH source AS (
+			SELECT embedding FROM article
+			WHERE id = @id AND embedding IS NOT NULL AND embedding_model = @model AND embedding_dimensions = @dimensions
+		),
+		source_tags AS (
+			SELECT tag_id FROM article_tag WHERE article_id = @id
+		),
+		candidates AS (
+			SELECT a.id, a.title, a.slug, a.tag, a.publish_at, a.created_at,
+			       1 - (a.embedding <=> source.embedding) AS similarity
+			FROM article a, source
+			WHERE a.id <> @id AND a.is_deleted = false AND a.status = @status AND a.embedding IS NOT NULL
+			  AND a.embedding_model = @model AND a.embedding_dimensions = @dimensions
+			ORDER BY a.embedding <=> source.embedding ASC
+			LIMIT @candidates
+		)
+		SELECT c.id, c.title, c.slug, c.tag, c.publish_at, c.created_at, c.similarity,
+		       COUNT(st.tag_id) AS shared_tags,
+		       c.similarity + @boost * LEAST(COUNT(st.tag_id), @maxShared) AS score
+		FROM candidates c
+		LEFT JOIN article_tag atg ON atg.article_id = c.id
+		LEFT JOIN source_tags st ON st.tag_id = atg.tag_id
+		GROUP BY c.id, c.title, c.slug, c.tag, c.publish_at, c.created_at, c.similarity
+		ORDER BY score DESC, c.id DESC
+		LIMIT @limit
+	`
+	err := ah.DB.Raw(sqlQuery, map[string]interface{}{
+		"id":         articleID,
+		"status":     models.ArticleStatusPublished,
+		"model":      version.Model,
+		"dimensions": version.Dimensions,
+		"candidates": limit * relatedCandidateFactor,
+		"boost":      relatedTagBoost,
+		"maxShared":  relatedMaxSharedTags,
+		"limit":      limit,
+	}).Scan(&related).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if len(related) == 0 {
+		// 未向量化时退回到共同标签推荐
+		err = ah.DB.Raw(`
+			SELECT a.id, a.title, a.slug, a.tag, a.publish_at, a.created_at,
+			       0 AS similarity, COUNT(*) AS shared_tags,
+			       @boost * LEAST(COUNT(*), @maxShared) AS score
+			FROM article a
+			JOIN article_tag atg ON atg.article_id = a.id
+			WHERE atg.tag_id IN (SELECT tag_id FROM article_tag WHERE article_id = @id)
+			  AND a.id <> @id AND a.is_deleted = false AND a.status = @status
+			GROUP BY a.id, a.title, a.slug, a.tag, a.publish_at, a.created_at
+			ORDER BY shared_tags DESC, a.created_at DESC
+			LIMIT @limit
+		`, map[string]interface{}{
+			"id":        articleID,
+			"status":    models.ArticleStatusPublished,
+			"boost":     relatedTagBoost,
+			"maxShared": relatedMaxSharedTags,
+			"limit":     limit,
+		}).Scan(&related).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if related == nil {
+		related = []RelatedArticle{}
+	}
+	for i := range related {
+		related[i].Tags = parseTagNames(related[i].Tag)
+	}
+	return related, nil
+}
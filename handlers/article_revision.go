@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/kafka"
+	"blog-server-go/models"
+	"errors"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// revisionEditor 修订操作人
+type revisionEditor struct {
+	ID       string
+	Username string
+}
+
+// editorFromCtx 从登录信息中获取操作人
+func editorFromCtx(c *fiber.Ctx) revisionEditor {
+	userID, _ := c.Locals("userId").(string)
+	username, _ := c.Locals("username").(string)
+	return revisionEditor{ID: userID, Username: username}
+}
+
+// recordArticleRevision 为文章的当前内容追加一条修订记录
+// previous 为修改前的文章，若该文章尚无修订记录则先把它保存为基线版本；内容未变化时不追加
+func recordArticleRevision(tx *gorm.DB, previous *models.Article, article *models.Article, source string, editor revisionEditor, restoredFrom int) error {
+	var latest models.ArticleRevision
+	result := tx.Where("article_id = ?", article.ID).Order("version desc").Limit(1).Find(&latest)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	version := latest.Version
+	if result.RowsAffected == 0 && previous != nil {
+		version++
+		baseline := models.ArticleRevision{
+			ArticleID: article.ID,
+			Version:   version,
+			Title:     previous.Title,
+			Content:   previous.Content,
+			Tag:       previous.Tag,
+			Source:    models.RevisionSourceBaseline,
+		}
+		if err := tx.Create(&baseline).Error; err != nil {
+			return err
+		}
+		latest = baseline
+	}
+
+	if version > 0 && latest.Title == article.Title && latest.Content == article.Content && latest.Tag == article.Tag {
+		return nil
+	}
+
+	revision := models.ArticleRevision{
+		BaseModel:    models.BaseModel{CreatedBy: models.SnowflakeID(editor.ID)},
+		ArticleID:    article.ID,
+		Version:      version + 1,
+		Title:        article.Title,
+		Content:      article.Content,
+		Tag:          article.Tag,
+		Source:       source,
+		Editor:       editor.Username,
+		RestoredFrom: restoredFrom,
+	}
+	return tx.Create(&revision).Error
+}
+
+// findArticleRevision 按版本号查询修订
+func (ah *ArticleHandler) findArticleRevision(articleID string, versionStr string) (*models.ArticleRevision, error) {
+	version, err := strconv.Atoi(versionStr)
+	if err != nil {
+		return nil, &common.BusinessException{Code: 5000, Message: "Invalid revision version"}
+	}
+	var revision models.ArticleRevision
+	result := ah.DB.Where("article_id = ? AND version = ?", articleID, version).Take(&revision)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, &common.BusinessException{Code: 5000, Message: "Revision not found"}
+		}
+		return nil, result.Error
+	}
+	return &revision, nil
+}
+
+// GetArticleRevisions 获取文章的修订列表（不含正文）
+func (ah *ArticleHandler) GetArticleRevisions(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var revisions []models.ArticleRevision
+	result := ah.DB.Select("id,article_id,version,title,tag,source,editor,restored_from,created_at,created_by").
+		Where("article_id = ?", id).Order("version desc").Find(&revisions)
+	if result.Error != nil {
+		log.Errorf("Failed to retrieve article revisions: %v", result.Error)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	return c.JSON(revisions)
+}
+
+// GetArticleRevision 获取某个版本的完整内容
+func (ah *ArticleHandler) GetArticleRevision(c *fiber.Ctx) error {
+	revision, err := ah.findArticleRevision(c.Params("id"), c.Params("version"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(revision)
+}
+
+// DiffArticleRevisions 对比两个版本的行级差异，to 为空时与当前文章对比
+func (ah *ArticleHandler) DiffArticleRevisions(c *fiber.Ctx) error {
+	id := c.Params("id")
+	from, err := ah.findArticleRevision(id, c.Query("from"))
+	if err != nil {
+		return err
+	}
+
+	toTitle, toContent, toTag, toVersion := "", "", "", 0
+	if c.Query("to") != "" {
+		to, err := ah.findArticleRevision(id, c.Query("to"))
+		if err != nil {
+			return err
+		}
+		toTitle, toContent, toTag, toVersion = to.Title, to.Content, to.Tag, to.Version
+	} else {
+		var article models.Article
+		if err := ah.DB.Select("id,title,content,tag").Take(&article, "id = ?", id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+			}
+			log.Errorf("Failed to retrieve article: %v", err)
+			return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+		}
+		toTitle, toContent, toTag = article.Title, article.Content, article.Tag
+	}
+
+	return c.JSON(fiber.Map{
+		"from": from.Version,
+		"to":   toVersion,
+		"title": fiber.Map{
+			"old": from.Title,
+			"new": toTitle,
+		},
+		"tag": fiber.Map{
+			"old": from.Tag,
+			"new": toTag,
+		},
+		"lines": common.DiffLines(from.Content, toContent),
+	})
+}
+
+// RestoreArticleRevision 将文章恢复到指定版本，并走正常的文章更新流程
+func (ah *ArticleHandler) RestoreArticleRevision(c *fiber.Ctx) error {
+	id := c.Params("id")
+	revision, err := ah.findArticleRevision(id, c.Params("version"))
+	if err != nil {
+		return err
+	}
+
+	var article models.Article
+	err = ah.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Take(&article, "id = ?", id).Error; err != nil {
+			return err
+		}
+		previous := article
+		if err := tx.Model(&article).Updates(map[string]interface{}{
+			"title":   revision.Title,
+			"content": revision.Content,
+			"tag":     revision.Tag,
+		}).Error; err != nil {
+			return err
+		}
+		article.Title, article.Content, article.Tag = revision.Title, revision.Content, revision.Tag
+		if err := syncArticleTags(tx, article.ID, parseTagNames(revision.Tag)); err != nil {
+			return err
+		}
+		return recordArticleRevision(tx, &previous, &article, models.RevisionSourceRestore, editorFromCtx(c), revision.Version)
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+		}
+		log.Errorf("Failed to restore article revision: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	ah.KafkaProducer.ProduceMessage(kafka.ArticleUpdateTopic, "id", id)
+	return c.JSON(article)
+}
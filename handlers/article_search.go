@@ -0,0 +1,430 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	searchModeHybrid  = "hybrid"
+	searchModeKeyword = "keyword"
+	searchModeVector  = "vector"
+
+	// hybridRRFK reciprocal-rank fusion 的平滑常数
+	hybridRRFK = 60
+	// hybridCandidateLimit 每个检索器召回的最少候选数，分页越深召回越多
+	hybridCandidateLimit = 20
+	// hybridMinSimilarity 向量召回的最低相似度，避免完全无关的文章混入
+	hybridMinSimilarity = 0.35
+	// hybridSnippetLimit 仅由向量命中的文章返回的片段字节上限
+	hybridSnippetLimit = 300
+
+	searchDefaultLimit = 20
+	searchMaxLimit     = 100
+)
+
+// searchFieldAliases 搜索排序与分面参数允许的字段，兼容驼峰写法
+var searchFieldAliases = map[string]string{
+	"tags":       "tags",
+	"created_at": "created_at",
+	"createdAt":  "created_at",
+	"view_count": "view_count",
+	"viewCount":  "view_count",
+}
+
+// articleSearchQuery 搜索参数，同时用于 Meilisearch 过滤和向量召回后的数据库过滤
+type articleSearchQuery struct {
+	Keyword  string
+	Tag      string
+	From     *time.Time
+	To       *time.Time
+	MinViews int
+	Sort     []string
+	Facets   []string
+	Offset   int64
+	Limit    int64
+	// Page 大于 0 时按页分页，Meilisearch 返回精确的总数
+	Page int64
+}
+
+// articleSearchResult 搜索结果
+type articleSearchResult struct {
+	Hits       []map[string]any
+	Total      int64
+	Facets     map[string]map[string]int64
+	FacetStats map[string]SearchFacetStat
+}
+
+// SearchPage 带分页和分面统计的搜索响应
+type SearchPage struct {
+	Items      []map[string]any            `json:"items"`
+	Total      int64                       `json:"total"`
+	Offset     int64                       `json:"offset"`
+	Limit      int64                       `json:"limit"`
+	Page       int64                       `json:"page,omitempty"`
+	TotalPages int64                       `json:"totalPages,omitempty"`
+	Facets     map[string]map[string]int64 `json:"facets,omitempty"`
+	FacetStats map[string]SearchFacetStat  `json:"facetStats,omitempty"`
+}
+
+// SearchFacetStat 数值字段的取值范围
+type SearchFacetStat struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// parseArticleSearchQuery 解析搜索参数
+// 带 page、offset 或 facets 参数时 paged 为 true，响应为 SearchPage 结构，否则保持返回数组
+func parseArticleSearchQuery(c *fiber.Ctx) (query articleSearchQuery, paged bool, err error) {
+	query = articleSearchQuery{
+		Keyword: strings.TrimSpace(c.Query("keyword")),
+		Tag:     strings.TrimSpace(c.Query("tag")),
+		Limit:   searchDefaultLimit,
+	}
+	if query.Keyword == "" {
+		return query, false, common.NewBusinessException(5000, "keyword is required")
+	}
+	args := c.Context().QueryArgs()
+	paged = args.Has("page") || args.Has("offset") || args.Has("facets")
+
+	if limitStr := c.Query("limit"); limitStr != "" {
+		limit, err := strconv.ParseInt(limitStr, 10, 64)
+		if err != nil || limit <= 0 {
+			return query, paged, common.NewBusinessException(5000, "Invalid limit value")
+		}
+		query.Limit = min(limit, searchMaxLimit)
+	}
+	if args.Has("page") && args.Has("offset") {
+		return query, paged, common.NewBusinessException(5000, "page and offset cannot be used together")
+	}
+	if pageStr := c.Query("page"); pageStr != "" {
+		page, err := strconv.ParseInt(pageStr, 10, 64)
+		if err != nil || page <= 0 {
+			return query, paged, common.NewBusinessException(5000, "Invalid page value")
+		}
+		query.Page = page
+		query.Offset = (page - 1) * query.Limit
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		offset, err := strconv.ParseInt(offsetStr, 10, 64)
+		if err != nil || offset < 0 {
+			return query, paged, common.NewBusinessException(5000, "Invalid offset value")
+		}
+		query.Offset = offset
+	}
+
+	// 按创建时间范围过滤，to 只给日期时包含当天
+	if from := c.Query("from"); from != "" {
+		fromTime, _, err := parseDateParam(from)
+		if err != nil {
+			return query, paged, common.NewBusinessException(5000, "Invalid from value")
+		}
+		query.From = &fromTime
+	}
+	if to := c.Query("to"); to != "" {
+		toTime, dateOnly, err := parseDateParam(to)
+		if err != nil {
+			return query, paged, common.NewBusinessException(5000, "Invalid to value")
+		}
+		if dateOnly {
+			toTime = toTime.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		query.To = &toTime
+	}
+	if minViews := c.Query("minViews"); minViews != "" {
+		value, err := strconv.Atoi(minViews)
+		if err != nil || value < 0 {
+			return query, paged, common.NewBusinessException(5000, "Invalid minViews value")
+		}
+		query.MinViews = value
+	}
+
+	// sort=view_count:desc,created_at:desc，不指定方向时为降序
+	for _, item := range strings.Split(c.Query("sort"), ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		name, direction, _ := strings.Cut(item, ":")
+		field, ok := searchFieldAliases[name]
+		if !ok || field == "tags" {
+			return query, paged, common.NewBusinessException(5000, "Invalid sort field")
+		}
+		direction = strings.ToLower(direction)
+		if direction == "" {
+			direction = "desc"
+		}
+		if direction != "asc" && direction != "desc" {
+			return query, paged, common.NewBusinessException(5000, "Invalid sort direction")
+		}
+		query.Sort = append(query.Sort, field+":"+direction)
+	}
+	for _, name := range strings.Split(c.Query("facets"), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		field, ok := searchFieldAliases[name]
+		if !ok {
+			return query, paged, common.NewBusinessException(5000, "Invalid facet field")
+		}
+		query.Facets = append(query.Facets, field)
+	}
+	return query, paged, nil
+}
+
+// filtered 是否带有过滤条件
+func (q articleSearchQuery) filtered() bool {
+	return q.Tag != "" || q.From != nil || q.To != nil || q.MinViews > 0
+}
+
+// meiliFilter Meilisearch 过滤表达式，多个条件之间为 AND
+func (q articleSearchQuery) meiliFilter() interface{} {
+	var filters []string
+	if q.Tag != "" {
+		tagJSON, _ := json.Marshal(q.Tag)
+		filters = append(filters, "tags = "+string(tagJSON))
+	}
+	if q.From != nil {
+		filters = append(filters, fmt.Sprintf("created_at >= %d", q.From.Unix()))
+	}
+	if q.To != nil {
+		filters = append(filters, fmt.Sprintf("created_at <= %d", q.To.Unix()))
+	}
+	if q.MinViews > 0 {
+		filters = append(filters, fmt.Sprintf("view_count >= %d", q.MinViews))
+	}
+	if len(filters) == 0 {
+		return nil
+	}
+	return filters
+}
+
+// applyFilter 在数据库查询上应用同样的过滤条件
+func (q articleSearchQuery) applyFilter(db *gorm.DB, query *gorm.DB) *gorm.DB {
+	if q.Tag != "" {
+		query = query.Where("id IN (?)", taggedArticleIDs(db, q.Tag))
+	}
+	if q.From != nil {
+		query = query.Where("created_at >= ?", *q.From)
+	}
+	if q.To != nil {
+		query = query.Where("created_at <= ?", *q.To)
+	}
+	if q.MinViews > 0 {
+		query = query.Where("view_count >= ?", q.MinViews)
+	}
+	return query
+}
+
+// newSearchPage 组装分页响应
+func newSearchPage(query articleSearchQuery, result *articleSearchResult) SearchPage {
+	page := SearchPage{
+		Items:      result.Hits,
+		Total:      result.Total,
+		Offset:     query.Offset,
+		Limit:      query.Limit,
+		Page:       query.Page,
+		Facets:     result.Facets,
+		FacetStats: result.FacetStats,
+	}
+	if query.Page > 0 {
+		page.TotalPages = (result.Total + query.Limit - 1) / query.Limit
+	}
+	return page
+}
+
+// hybridSearch 同时使用 Meilisearch 关键词搜索和 pgvector 语义搜索，按 RRF 融合排序后分页
+// 任一检索器不可用时退化为另一个并返回实际使用的模式；关键词命中的文章保留高亮
+// 融合结果按相关度排序，sort 参数不生效；分面统计来自关键词检索
+func (ah *ArticleHandler) hybridSearch(query articleSearchQuery) (*articleSearchResult, string, error) {
+	candidates := min(max(query.Offset+query.Limit, hybridCandidateLimit), searchMaxLimit)
+	keywordQuery := query
+	keywordQuery.Sort, keywordQuery.Offset, keywordQuery.Page, keywordQuery.Limit = nil, 0, 0, candidates
+
+	var (
+		wg                    sync.WaitGroup
+		keywordResult         *articleSearchResult
+		vectorHits            []map[string]any
+		keywordErr, vectorErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		keywordResult, keywordErr = ah.keywordSearch(keywordQuery)
+	}()
+	go func() {
+		defer wg.Done()
+		vectorHits, vectorErr = ah.vectorSearch(query, int(candidates))
+	}()
+	wg.Wait()
+
+	mode := searchModeHybrid
+	switch {
+	case keywordErr != nil && vectorErr != nil:
+		log.Errorf("Hybrid search failed, keyword: %v, vector: %v", keywordErr, vectorErr)
+		return nil, "", keywordErr
+	case keywordErr != nil:
+		log.Warnf("Keyword search unavailable, falling back to vector search: %v", keywordErr)
+		mode = searchModeVector
+		keywordResult = &articleSearchResult{}
+	case vectorErr != nil:
+		log.Warnf("Vector search unavailable, falling back to keyword search: %v", vectorErr)
+		mode = searchModeKeyword
+	}
+
+	fused := fuseByReciprocalRank(keywordResult.Hits, vectorHits, int(candidates))
+	result := &articleSearchResult{
+		Hits:       []map[string]any{},
+		Total:      int64(len(fused)),
+		Facets:     keywordResult.Facets,
+		FacetStats: keywordResult.FacetStats,
+	}
+	if query.Offset < int64(len(fused)) {
+		result.Hits = fused[query.Offset:min(query.Offset+query.Limit, int64(len(fused)))]
+	}
+	return result, mode, nil
+}
+
+// vectorSearch 语义搜索：优先按片段召回并按文章去重，尚未切分片段时按整篇文章召回
+func (ah *ArticleHandler) vectorSearch(searchQuery articleSearchQuery, limit int) ([]map[string]any, error) {
+	queryEmbedding, err := ah.GenerateEmbedding(searchQuery.Keyword)
+	if err != nil {
+		return nil, err
+	}
+
+	type vectorHit struct {
+		article     models.Article
+		headingPath string
+		snippet     string
+		similarity  float32
+	}
+	var hits []vectorHit
+	seen := make(map[models.SnowflakeID]bool)
+
+	chunks, err := ah.searchChunksByVector(queryEmbedding, limit*ragChunksPerQuery)
+	if err != nil {
+		return nil, err
+	}
+	for _, chunk := range chunks {
+		if seen[chunk.ArticleID] || chunk.Similarity < hybridMinSimilarity {
+			continue
+		}
+		seen[chunk.ArticleID] = true
+		hits = append(hits, vectorHit{
+			article:     models.Article{BaseModel: models.BaseModel{ID: chunk.ArticleID}, Title: chunk.Title, Slug: chunk.Slug},
+			headingPath: chunk.HeadingPath,
+			snippet:     chunk.Content,
+			similarity:  chunk.Similarity,
+		})
+	}
+	if len(chunks) == 0 {
+		articles, err := ah.searchArticlesByEmbedding(queryEmbedding, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, article := range articles {
+			if article.Similarity < hybridMinSimilarity {
+				continue
+			}
+			hits = append(hits, vectorHit{article: article.Article, snippet: article.Content, similarity: article.Similarity})
+		}
+	}
+	if len(hits) == 0 {
+		return []map[string]any{}, nil
+	}
+
+	// 片段结果不含标签，统一补齐并按搜索条件过滤
+	ids := make([]models.SnowflakeID, 0, len(hits))
+	for _, hit := range hits {
+		ids = append(ids, hit.article.ID)
+	}
+	query := searchQuery.applyFilter(ah.DB, ah.DB.Model(&models.Article{}).Select("id,tag").Where("id IN ?", ids))
+	var tagged []models.Article
+	if err := query.Find(&tagged).Error; err != nil {
+		return nil, err
+	}
+	tags := make(map[models.SnowflakeID]string, len(tagged))
+	for _, article := range tagged {
+		tags[article.ID] = article.Tag
+	}
+
+	results := make([]map[string]any, 0, len(hits))
+	for _, hit := range hits {
+		articleTag, ok := tags[hit.article.ID]
+		if !ok {
+			continue
+		}
+		results = append(results, map[string]any{
+			"id":          string(hit.article.ID),
+			"title":       hit.article.Title,
+			"content":     common.TruncateUTF8(hit.snippet, hybridSnippetLimit),
+			"tag":         articleTag,
+			"tags":        parseTagNames(articleTag),
+			"headingPath": hit.headingPath,
+			"similarity":  hit.similarity,
+		})
+		if len(results) >= limit {
+			break
+		}
+	}
+	return results, nil
+}
+
+// fuseByReciprocalRank 按 RRF 融合两组结果：score = Σ 1 / (k + rank)
+// 同一篇文章优先使用关键词结果（带高亮），并补充向量结果中的相似度和标题路径
+func fuseByReciprocalRank(keywordHits []map[string]any, vectorHits []map[string]any, limit int) []map[string]any {
+	type fused struct {
+		item    map[string]any
+		score   float64
+		sources []string
+	}
+	merged := make(map[string]*fused)
+	order := make([]string, 0, len(keywordHits)+len(vectorHits))
+	add := func(hits []map[string]any, source string) {
+		for rank, hit := range hits {
+			id, _ := hit["id"].(string)
+			entry, ok := merged[id]
+			if !ok {
+				entry = &fused{item: hit}
+				merged[id] = entry
+				order = append(order, id)
+			} else {
+				for _, key := range []string{"similarity", "headingPath"} {
+					if value, exists := hit[key]; exists {
+						entry.item[key] = value
+					}
+				}
+			}
+			entry.score += 1.0 / float64(hybridRRFK+rank+1)
+			entry.sources = append(entry.sources, source)
+		}
+	}
+	add(keywordHits, searchModeKeyword)
+	add(vectorHits, searchModeVector)
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return merged[order[i]].score > merged[order[j]].score
+	})
+	if len(order) > limit {
+		order = order[:limit]
+	}
+
+	results := make([]map[string]any, 0, len(order))
+	for _, id := range order {
+		entry := merged[id]
+		entry.item["score"] = entry.score
+		entry.item["matchedBy"] = entry.sources
+		results = append(results, entry.item)
+	}
+	return results
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/kafka"
+	"blog-server-go/models"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ensureArticleID 提前生成文章 ID，slug 兜底时需要用到
+func ensureArticleID(article *models.Article) error {
+	if article.ID != "" {
+		return nil
+	}
+	id, err := common.GenerateID()
+	if err != nil {
+		return err
+	}
+	article.ID = models.SnowflakeID(id)
+	return nil
+}
+
+// resolveArticleSlug 计算文章 slug：优先使用管理员指定的值，否则由标题生成，冲突时追加序号
+func resolveArticleSlug(tx *gorm.DB, articleID models.SnowflakeID, requested string, title string) (string, error) {
+	base := common.Slugify(requested)
+	if base == "" {
+		base = common.Slugify(title)
+	}
+	if base == "" {
+		base = "post-" + string(articleID)
+	}
+
+	candidate := base
+	for n := 2; ; n++ {
+		var count int64
+		if err := tx.Model(&models.Article{}).Where("slug = ? AND id <> ?", candidate, articleID).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			if err := tx.Model(&models.ArticleSlugRedirect{}).Where("old_slug = ? AND article_id <> ?", candidate, articleID).Count(&count).Error; err != nil {
+				return "", err
+			}
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// saveArticleSlugRedirect slug 变更后记录旧 slug，便于前端 301 跳转
+func saveArticleSlugRedirect(tx *gorm.DB, articleID models.SnowflakeID, oldSlug string, newSlug string) error {
+	if oldSlug == "" || oldSlug == newSlug {
+		return nil
+	}
+	// 重新启用的旧 slug 不再需要跳转
+	if err := tx.Where("old_slug = ?", newSlug).Delete(&models.ArticleSlugRedirect{}).Error; err != nil {
+		return err
+	}
+	redirect := models.ArticleSlugRedirect{OldSlug: oldSlug, ArticleID: articleID}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "old_slug"}},
+		DoUpdates: clause.AssignmentColumns([]string{"article_id", "updated_at"}),
+	}).Create(&redirect).Error
+}
+
+// revalidateOldSlug slug 变更后重新生成旧链接的页面，使其改为 301 跳转
+func (ah *ArticleHandler) revalidateOldSlug(oldSlug string, newSlug string) {
+	if oldSlug == "" || oldSlug == newSlug {
+		return
+	}
+	ah.KafkaProducer.ProduceMessage(kafka.RevalidateUpdateTopic, "path", "/post/"+oldSlug)
+}
+
+// isAdminRequest 请求是否携带管理员 token，由全局的 AuthMiddleware 解析
+func isAdminRequest(c *fiber.Ctx) bool {
+	isAdmin, _ := c.Locals("isAdmin").(bool)
+	return isAdmin
+}
+
+// GetArticleBySlug 通过 slug 获取文章；命中旧 slug 时返回当前 slug，由前端 301 跳转
+// 非管理员只能访问已发布的文章
+func (ah *ArticleHandler) GetArticleBySlug(c *fiber.Ctx) error {
+	slug := strings.TrimSpace(c.Params("slug"))
+	visible := ah.DB.Where("is_deleted", false)
+	if !isAdminRequest(c) {
+		visible = publishedArticles(ah.DB)
+	}
+	// 下面的两次查询共用可见范围的条件
+	visible = visible.Session(&gorm.Session{})
+	var article models.Article
+	result := visible.Where("slug = ?", slug).Take(&article)
+	if result.Error == nil {
+		articles := []models.Article{article}
+		attachSummaries(ah.DB, articles)
+		article = articles[0]
+		article.Tags = parseTagNames(article.Tag)
+		applyTranslation(ah.DB, &article, c.Query("lang"))
+		series, err := loadSeriesNavigation(ah.DB, article.ID)
+		if err != nil {
+			log.Errorf("Failed to load series navigation: %v", err)
+		}
+		article.Series = series
+		return c.JSON(article)
+	}
+	if !errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		log.Errorf("Failed to retrieve article by slug: %v", result.Error)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	var redirect models.ArticleSlugRedirect
+	if err := ah.DB.Where("old_slug = ?", slug).Take(&redirect).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+		}
+		log.Errorf("Failed to retrieve slug redirect: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	if err := visible.Select("id,slug").Take(&article, "id = ?", redirect.ArticleID).Error; err != nil || article.Slug == "" {
+		return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"redirect":   true,
+		"statusCode": fiber.StatusMovedPermanently,
+		"id":         article.ID,
+		"slug":       article.Slug,
+	})
+}
+
+// GenerateMissingSlugs 为尚未设置 slug 的文章补全 slug
+func (ah *ArticleHandler) GenerateMissingSlugs(c *fiber.Ctx) error {
+	var articles []models.Article
+	if err := ah.DB.Select("id,title").Where("slug IS NULL OR slug = ''").Find(&articles).Error; err != nil {
+		log.Errorf("Failed to query articles without slug: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	updated := 0
+	for _, article := range articles {
+		err := ah.DB.Transaction(func(tx *gorm.DB) error {
+			slug, err := resolveArticleSlug(tx, article.ID, "", article.Title)
+			if err != nil {
+				return err
+			}
+			return tx.Model(&models.Article{}).Where("id = ?", article.ID).UpdateColumn("slug", slug).Error
+		})
+		if err != nil {
+			log.Errorf("Failed to generate slug for article %s: %v", article.ID, err)
+			continue
+		}
+		updated++
+	}
+
+	return c.JSON(fiber.Map{
+		"total":   len(articles),
+		"updated": updated,
+	})
+}
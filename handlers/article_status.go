@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// resolveArticleStatus 校验并补全文章状态，同时同步 IsActive
+// 未传 status 时沿用旧的 isActive 语义：true 为已发布，false 为草稿
+func resolveArticleStatus(article *models.Article) error {
+	now := time.Now()
+	if article.Status == "" {
+		if article.IsActive {
+			article.Status = models.ArticleStatusPublished
+		} else {
+			article.Status = models.ArticleStatusDraft
+		}
+	}
+
+	switch article.Status {
+	case models.ArticleStatusDraft, models.ArticleStatusArchived:
+	case models.ArticleStatusScheduled:
+		if article.PublishAt == nil {
+			return &common.BusinessException{Code: 5000, Message: "publishAt is required for scheduled article"}
+		}
+		if !article.PublishAt.After(now) {
+			article.Status = models.ArticleStatusPublished
+		}
+	case models.ArticleStatusPublished:
+		if article.PublishAt == nil {
+			article.PublishAt = &now
+		}
+	default:
+		return &common.BusinessException{Code: 5000, Message: "Invalid article status"}
+	}
+
+	article.IsActive = article.Status == models.ArticleStatusPublished
+	return nil
+}
+
+// publishedArticles 只保留已发布且未删除的文章
+func publishedArticles(db *gorm.DB) *gorm.DB {
+	return db.Where("is_deleted", false).Where("status = ?", models.ArticleStatusPublished)
+}
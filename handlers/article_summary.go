@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"blog-server-go/services"
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+const (
+	// summaryRegenerateLockKey 批量重新生成摘要时持有的锁，避免重复执行
+	summaryRegenerateLockKey = "articleSummary:regenerate:lock"
+	summaryRegenerateTimeout = 2 * time.Hour
+)
+
+// attachSummaries 为文章列表补充短摘要和阅读时间
+func attachSummaries(db *gorm.DB, articles []models.Article) {
+	ids := make([]models.SnowflakeID, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+	summaries, err := services.LoadArticleSummaries(db, ids)
+	if err != nil {
+		log.Errorf("Failed to load article summaries: %v", err)
+		return
+	}
+	for i := range articles {
+		if summary, ok := summaries[articles[i].ID]; ok {
+			articles[i].Summary = summary.ShortSummary
+			articles[i].ReadingMinutes = summary.ReadingMinutes
+		}
+	}
+}
+
+// shortSummaries 批量读取文章的短摘要，读取失败时返回空结果
+func shortSummaries(db *gorm.DB, ids []models.SnowflakeID) map[models.SnowflakeID]string {
+	result := make(map[models.SnowflakeID]string, len(ids))
+	summaries, err := services.LoadArticleSummaries(db, ids)
+	if err != nil {
+		log.Errorf("Failed to load article summaries: %v", err)
+		return result
+	}
+	for id, summary := range summaries {
+		result[id] = summary.ShortSummary
+	}
+	return result
+}
+
+// GetArticleSummary 获取文章摘要，摘要不存在或正文已变化时重新生成
+// length=short（默认）返回短摘要，length=long 返回长摘要，length=all 返回完整记录
+func (ah *ArticleHandler) GetArticleSummary(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var article models.Article
+	// 只为已发布的文章生成摘要，避免公开接口泄露草稿内容
+	err := ah.DB.Select("id,title,content").Where("is_deleted = ? AND status = ?", false, models.ArticleStatusPublished).
+		Take(&article, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Article not found"})
+		}
+		log.Errorf("Failed to retrieve article: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	summary, _, err := services.GenerateArticleSummary(context.Background(), ah.DB, ah.AI.Summary, article, false)
+	if err != nil {
+		log.Errorf("Failed to generate summary of article %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to generate summary"})
+	}
+
+	switch c.Query("length") {
+	case "long":
+		return c.JSON(summary.LongSummary)
+	case "all":
+		return c.JSON(summary)
+	default:
+		return c.JSON(summary.ShortSummary)
+	}
+}
+
+// UpdateArticleSummary 手动修改摘要，正文变化前不会被自动生成的摘要覆盖
+// 入参 {summary: "短摘要", longSummary: "长摘要"}，longSummary 为空时与短摘要相同
+func (ah *ArticleHandler) UpdateArticleSummary(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	var input struct {
+		Summary     string `json:"summary"`
+		LongSummary string `json:"longSummary"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(common.NewResponse(fiber.StatusBadRequest, "Invalid request body", nil))
+	}
+	if input.Summary == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(common.NewResponse(fiber.StatusBadRequest, "Summary is required", nil))
+	}
+	if input.LongSummary == "" {
+		input.LongSummary = input.Summary
+	}
+
+	var article models.Article
+	if err := ah.DB.Select("id,content").Take(&article, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Article not found"})
+		}
+		log.Errorf("Failed to retrieve article: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	var existing models.ArticleSummary
+	ah.DB.Select("id,created_at").Where("article_id = ?", article.ID).Take(&existing)
+	summary := models.ArticleSummary{
+		BaseModel:    existing.BaseModel,
+		ArticleID:    article.ID,
+		ShortSummary: input.Summary,
+		LongSummary:  input.LongSummary,
+		Model:        models.SummaryModelManual,
+		ContentHash:  services.SummaryContentHash(article.Content),
+	}
+	summary.WordCount, summary.ReadingMinutes = common.ReadingStats(article.Content)
+	if err := services.SaveArticleSummary(ah.DB, &summary); err != nil {
+		log.Errorf("Failed to save article summary: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(common.NewResponse(fiber.StatusInternalServerError, "Failed to update article summary", nil))
+	}
+	return c.JSON("Article summary updated successfully")
+}
+
+// RegenerateArticleSummaries 在后台为所有文章重新生成摘要，正文未变化的文章跳过
+// 请求体可选：force 为 true 时忽略正文哈希全部重新生成
+func (ah *ArticleHandler) RegenerateArticleSummaries(c *fiber.Ctx) error {
+	var req struct {
+		Force bool `json:"force"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+		}
+	}
+
+	locked, err := ah.Redis.SetNX(context.Background(), summaryRegenerateLockKey, "1", summaryRegenerateTimeout).Result()
+	if err != nil {
+		log.Errorf("Failed to acquire summary lock: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	if !locked {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Summary regeneration is already running"})
+	}
+
+	go func() {
+		defer ah.Redis.Del(context.Background(), summaryRegenerateLockKey)
+		ctx, cancel := context.WithTimeout(context.Background(), summaryRegenerateTimeout)
+		defer cancel()
+		result, err := services.RegenerateArticleSummaries(ctx, ah.DB, ah.AI.Summary, req.Force)
+		if err != nil {
+			log.Errorf("Failed to regenerate article summaries: %v", err)
+		}
+		log.Infof("Article summaries regenerated: %+v", result)
+	}()
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{"force": req.Force})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// parseTagNames 拆分逗号分隔的标签字符串，去除空白和重复项
+func parseTagNames(raw string) []string {
+	return common.SplitTagNames(raw)
+}
+
+func normalizeTagNames(names []string) []string {
+	return common.NormalizeTagNames(names)
+}
+
+// normalizeArticleTags 统一 tags 数组与旧的 tag 字符串，tags 优先
+func normalizeArticleTags(article *models.Article) {
+	if len(article.Tags) > 0 {
+		article.Tags = normalizeTagNames(article.Tags)
+	} else {
+		article.Tags = parseTagNames(article.Tag)
+	}
+	article.Tag = strings.Join(article.Tags, ",")
+}
+
+// syncArticleTags 将文章的标签写入 tag / article_tag 表
+func syncArticleTags(tx *gorm.DB, articleID models.SnowflakeID, names []string) error {
+	tagIDs := make([]models.SnowflakeID, 0, len(names))
+	for _, name := range names {
+		tag := models.Tag{Name: name}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "name"}},
+			DoNothing: true,
+		}).Create(&tag).Error; err != nil {
+			return err
+		}
+		if err := tx.Select("id").Where("name = ?", name).Take(&tag).Error; err != nil {
+			return err
+		}
+		tagIDs = append(tagIDs, tag.ID)
+	}
+
+	query := tx.Where("article_id = ?", articleID)
+	if len(tagIDs) > 0 {
+		query = query.Where("tag_id NOT IN ?", tagIDs)
+	}
+	if err := query.Delete(&models.ArticleTag{}).Error; err != nil {
+		return err
+	}
+	if len(tagIDs) == 0 {
+		return nil
+	}
+
+	links := make([]models.ArticleTag, 0, len(tagIDs))
+	for _, tagID := range tagIDs {
+		links = append(links, models.ArticleTag{ArticleID: articleID, TagID: tagID})
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// taggedArticleIDs 返回包含指定标签的文章 ID 子查询
+func taggedArticleIDs(db *gorm.DB, name string) *gorm.DB {
+	return db.Table("article_tag").Select("article_tag.article_id").
+		Joins("JOIN tag ON tag.id = article_tag.tag_id").
+		Where("tag.name = ?", name)
+}
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/kafka"
+	"blog-server-go/models"
+	"blog-server-go/services"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// articleTranslateTimeout 翻译整篇文章的超时时间
+const articleTranslateTimeout = 10 * time.Minute
+
+// applyTranslation 存在 lang 对应的译文时替换标题和正文，否则保持原文
+// 摘要和 SEO 描述只有中文，使用译文时不返回；阅读时长按译文重新计算
+func applyTranslation(db *gorm.DB, article *models.Article, lang string) {
+	if lang == "" {
+		return
+	}
+	locale, err := services.NormalizeLocale(lang)
+	if err != nil {
+		return
+	}
+	var translation models.ArticleTranslation
+	if err := db.Where("article_id = ? AND locale = ?", article.ID, locale).Take(&translation).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Errorf("Failed to load article translation: %v", err)
+		}
+		return
+	}
+	services.MarkTranslationStale(&translation, *article)
+	article.Title = translation.Title
+	article.Content = translation.Content
+	article.Summary = ""
+	article.MetaDescription = ""
+	_, article.ReadingMinutes = common.ReadingStats(translation.Content)
+	article.Lang = locale
+	article.TranslationStale = translation.Stale
+}
+
+// loadTranslationTarget 读取路由中的文章和语言；返回 false 时已写入错误响应
+func (ah *ArticleHandler) loadTranslationTarget(c *fiber.Ctx) (models.Article, string, bool) {
+	var article models.Article
+	locale, err := services.NormalizeLocale(c.Params("locale"))
+	if err != nil {
+		c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unsupported locale"})
+		return article, "", false
+	}
+	if err := ah.DB.Where("is_deleted", false).Take(&article, "id = ?", c.Params("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Article not found"})
+			return article, "", false
+		}
+		log.Errorf("Failed to retrieve article: %v", err)
+		c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+		return article, "", false
+	}
+	return article, locale, true
+}
+
+// GetArticleTranslations 文章的所有译文（不含正文），并标记原文更新后已过期的译文
+func (ah *ArticleHandler) GetArticleTranslations(c *fiber.Ctx) error {
+	var article models.Article
+	if err := ah.DB.Select("id,updated_at").Where("is_deleted", false).Take(&article, "id = ?", c.Params("id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Article not found"})
+		}
+		log.Errorf("Failed to retrieve article: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	var translations []models.ArticleTranslation
+	if err := ah.DB.Omit("content").Where("article_id = ?", article.ID).Order("locale").Find(&translations).Error; err != nil {
+		log.Errorf("Failed to retrieve article translations: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	for i := range translations {
+		services.MarkTranslationStale(&translations[i], article)
+	}
+	return c.JSON(translations)
+}
+
+// GetArticleTranslation 某种语言的完整译文
+func (ah *ArticleHandler) GetArticleTranslation(c *fiber.Ctx) error {
+	article, locale, ok := ah.loadTranslationTarget(c)
+	if !ok {
+		return nil
+	}
+
+	var translation models.ArticleTranslation
+	if err := ah.DB.Where("article_id = ? AND locale = ?", article.ID, locale).Take(&translation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Translation not found"})
+		}
+		log.Errorf("Failed to retrieve article translation: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	services.MarkTranslationStale(&translation, article)
+	return c.JSON(translation)
+}
+
+// TranslateArticle 使用翻译模型按章节翻译文章，覆盖该语言已有的译文
+// 管理员编辑过的译文默认不覆盖，返回 409，带 ?force=true 时强制重新翻译
+func (ah *ArticleHandler) TranslateArticle(c *fiber.Ctx) error {
+	article, locale, ok := ah.loadTranslationTarget(c)
+	if !ok {
+		return nil
+	}
+	if strings.TrimSpace(article.Content) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Article has no content"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), articleTranslateTimeout)
+	defer cancel()
+	translation, err := services.TranslateArticle(ctx, ah.DB, ah.AI.Translation, article, locale, c.QueryBool("force"))
+	if errors.Is(err, services.ErrTranslationEdited) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": "Translation has been edited, use force=true to overwrite"})
+	}
+	if err != nil {
+		log.Errorf("Failed to translate article %s into %s: %v", article.ID, locale, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to translate article"})
+	}
+	// 重新生成页面
+	ah.KafkaProducer.ProduceMessage(kafka.ArticleUpdateTopic, "id", string(article.ID))
+	return c.JSON(translation)
+}
+
+// UpdateArticleTranslation 管理员编辑或手动创建译文
+// 入参 {title, content, markCurrent}：markCurrent 为 true 时视为已按当前原文校对，清除过期标记
+func (ah *ArticleHandler) UpdateArticleTranslation(c *fiber.Ctx) error {
+	article, locale, ok := ah.loadTranslationTarget(c)
+	if !ok {
+		return nil
+	}
+
+	var input struct {
+		Title       string `json:"title"`
+		Content     string `json:"content"`
+		MarkCurrent bool   `json:"markCurrent"`
+	}
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid request"})
+	}
+	if strings.TrimSpace(input.Title) == "" || strings.TrimSpace(input.Content) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Title and content are required"})
+	}
+
+	var translation models.ArticleTranslation
+	err := ah.DB.Where("article_id = ? AND locale = ?", article.ID, locale).Take(&translation).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Errorf("Failed to retrieve article translation: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	// 新建的译文以当前原文为准
+	if errors.Is(err, gorm.ErrRecordNotFound) || input.MarkCurrent {
+		translation.SourceUpdatedAt = article.UpdatedAt
+	}
+	translation.ArticleID = article.ID
+	translation.Locale = locale
+	translation.Title = strings.TrimSpace(input.Title)
+	translation.Content = input.Content
+	translation.Edited = true
+	if err := services.SaveArticleTranslation(ah.DB, &translation); err != nil {
+		log.Errorf("Failed to save article translation: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	ah.KafkaProducer.ProduceMessage(kafka.ArticleUpdateTopic, "id", string(article.ID))
+	services.MarkTranslationStale(&translation, article)
+	return c.JSON(translation)
+}
+
+// DeleteArticleTranslation 删除某种语言的译文
+func (ah *ArticleHandler) DeleteArticleTranslation(c *fiber.Ctx) error {
+	locale, err := services.NormalizeLocale(c.Params("locale"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Unsupported locale"})
+	}
+	result := ah.DB.Where("article_id = ? AND locale = ?", c.Params("id"), locale).Delete(&models.ArticleTranslation{})
+	if result.Error != nil {
+		log.Errorf("Failed to delete article translation: %v", result.Error)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Translation not found"})
+	}
+	ah.KafkaProducer.ProduceMessage(kafka.ArticleUpdateTopic, "id", c.Params("id"))
+	return c.SendStatus(fiber.StatusNoContent)
+}
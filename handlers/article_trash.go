@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"blog-server-go/kafka"
+	"blog-server-go/models"
+	"blog-server-go/services"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// TrashedArticle 回收站列表项
+type TrashedArticle struct {
+	ID        models.SnowflakeID `json:"id"`
+	Title     string             `json:"title"`
+	Slug      string             `json:"slug"`
+	Status    string             `json:"status"`
+	TrashedAt *time.Time         `json:"trashedAt"`
+	PurgeAt   *time.Time         `json:"purgeAt"`
+}
+
+// DeleteArticle 将文章移入回收站，并从搜索索引中移除，同时清除向量、片段和摘要
+func (ah *ArticleHandler) DeleteArticle(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var article models.Article
+	if err := ah.DB.Select("id,slug").Where("is_deleted", false).Take(&article, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Article not found"})
+		}
+		log.Errorf("Failed to retrieve article: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	now := time.Now()
+	err := ah.DB.Model(&models.Article{}).Where("id = ?", article.ID).Updates(map[string]interface{}{
+		"is_deleted":           true,
+		"trashed_at":           now,
+		"embedding":            nil,
+		"embedding_model":      "",
+		"embedding_dimensions": 0,
+	}).Error
+	if err != nil {
+		log.Errorf("Failed to move article to trash: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	if err := ah.DB.Where("article_id = ?", article.ID).Delete(&models.ArticleChunk{}).Error; err != nil {
+		log.Errorf("Failed to delete article chunks: %v", err)
+	}
+	if err := ah.DB.Where("article_id = ?", article.ID).Delete(&models.ArticleSummary{}).Error; err != nil {
+		log.Errorf("Failed to delete article summary: %v", err)
+	}
+
+	ah.removeArticleArtifacts(article)
+	return c.JSON(fiber.Map{
+		"id":        article.ID,
+		"trashedAt": now,
+		"purgeAt":   services.TrashPurgeAt(&now),
+	})
+}
+
+// GetTrashedArticles 回收站列表，按移入时间倒序
+func (ah *ArticleHandler) GetTrashedArticles(c *fiber.Ctx) error {
+	var articles []models.Article
+	result := ah.DB.Select("id,title,slug,status,trashed_at").
+		Where("is_deleted", true).
+		Order("trashed_at DESC NULLS LAST").Order("id DESC").
+		Find(&articles)
+	if result.Error != nil {
+		log.Errorf("Failed to retrieve trashed articles: %v", result.Error)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	items := make([]TrashedArticle, 0, len(articles))
+	for _, article := range articles {
+		items = append(items, TrashedArticle{
+			ID:        article.ID,
+			Title:     article.Title,
+			Slug:      article.Slug,
+			Status:    article.Status,
+			TrashedAt: article.TrashedAt,
+			PurgeAt:   services.TrashPurgeAt(article.TrashedAt),
+		})
+	}
+	return c.JSON(items)
+}
+
+// RestoreArticle 从回收站恢复文章，重新加入搜索索引并重新生成向量和摘要
+func (ah *ArticleHandler) RestoreArticle(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var article models.Article
+	if err := ah.DB.Where("is_deleted", true).Take(&article, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Article not found in trash"})
+		}
+		log.Errorf("Failed to retrieve article: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	err := ah.DB.Model(&article).Updates(map[string]interface{}{
+		"is_deleted": false,
+		"trashed_at": nil,
+	}).Error
+	if err != nil {
+		log.Errorf("Failed to restore article: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	article.IsDeleted = false
+	article.TrashedAt = nil
+
+	// 写回搜索索引、重新生成向量并刷新页面
+	ah.KafkaProducer.ProduceMessage(kafka.ArticleUpdateTopic, "id", string(article.ID))
+	article.Tags = parseTagNames(article.Tag)
+	return c.JSON(article)
+}
+
+// PurgeArticle 永久删除回收站中的文章
+func (ah *ArticleHandler) PurgeArticle(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var article models.Article
+	if err := ah.DB.Select("id,slug").Where("is_deleted", true).Take(&article, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Article not found in trash"})
+		}
+		log.Errorf("Failed to retrieve article: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	if _, err := services.PurgeArticles(ah.DB, []models.SnowflakeID{article.ID}); err != nil {
+		log.Errorf("Failed to purge article: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	// 移入回收站时已清理过，这里再清理一次以防期间被重新写入
+	ah.removeArticleArtifacts(article)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// removeArticleArtifacts 清理文章的搜索索引、feed / sitemap / 相关文章缓存，并通知前端刷新页面
+func (ah *ArticleHandler) removeArticleArtifacts(article models.Article) {
+	ctx := context.Background()
+	if _, err := ah.Meili.Index(services.ArticleSearchIndex).DeleteDocument(string(article.ID), nil); err != nil {
+		log.Errorf("Failed to delete article from Meilisearch: %v", err)
+	}
+	ah.Redis.Del(ctx, feedCacheKey, sitemapCacheKey, relatedCacheKey)
+
+	paths := []string{"/", "/post/" + string(article.ID)}
+	if article.Slug != "" {
+		paths = append(paths, "/post/"+article.Slug)
+	}
+	ah.KafkaProducer.ProduceMessage(kafka.RevalidateUpdateTopic, "path", strings.Join(paths, ","))
+	ah.KafkaProducer.ProduceMessage(kafka.RevalidateUpdateTopic, "tag", "article")
+}
@@ -63,6 +63,10 @@ func (bch *BlogConfigHandler) SaveSiteConfig(c *fiber.Ctx) error {
 			}
 		}
 	}
+	// feed 和 sitemap 中包含站点地址、标题等配置
+	if err := bch.Redis.Del(ctx, feedCacheKey, sitemapCacheKey).Err(); err != nil {
+		log.Errorf("Failed to clear feed and sitemap cache: %v", err)
+	}
 	var paths []string
 	paths = append(paths, "/")
 	bch.KafkaProducer.ProduceMessage(kafka.RevalidateUpdateTopic, "path", strings.Join(paths, ","))
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
@@ -32,6 +33,7 @@ type discoursePostPayload struct {
 	TopicSlug      string  `json:"topic_slug"`
 	TopicTitle     string  `json:"topic_title"`
 	TopicArchetype string  `json:"topic_archetype"`
+	Username       string  `json:"username"`
 	Raw            string  `json:"raw"`
 	DeletedAt      *string `json:"deleted_at"`
 	Hidden         bool    `json:"hidden"`
@@ -109,8 +111,11 @@ func (dh *DiscourseWebhookHandler) upsertDiscourseArticle(post *discoursePostPay
 		return nil, false, result.Error
 	}
 
+	tags = normalizeTagNames(tags)
 	tagValue := strings.Join(tags, ",")
 	created := result.Error == gorm.ErrRecordNotFound
+	editor := revisionEditor{Username: post.Username}
+	now := time.Now()
 
 	if created {
 		article = models.Article{
@@ -118,9 +123,28 @@ func (dh *DiscourseWebhookHandler) upsertDiscourseArticle(post *discoursePostPay
 			Content:          post.Raw,
 			Tag:              tagValue,
 			IsActive:         true,
+			Status:           models.ArticleStatusPublished,
+			PublishAt:        &now,
 			DiscourseTopicID: post.TopicID,
 		}
-		if err := dh.DB.Omit("embedding").Create(&article).Error; err != nil {
+		if err := ensureArticleID(&article); err != nil {
+			return nil, false, err
+		}
+		err := dh.DB.Transaction(func(tx *gorm.DB) error {
+			slug, err := resolveArticleSlug(tx, article.ID, "", article.Title)
+			if err != nil {
+				return err
+			}
+			article.Slug = slug
+			if err := tx.Omit("embedding").Create(&article).Error; err != nil {
+				return err
+			}
+			if err := syncArticleTags(tx, article.ID, tags); err != nil {
+				return err
+			}
+			return recordArticleRevision(tx, nil, &article, models.RevisionSourceDiscourse, editor, 0)
+		})
+		if err != nil {
 			return nil, false, err
 		}
 		return &article, true, nil
@@ -131,11 +155,32 @@ func (dh *DiscourseWebhookHandler) upsertDiscourseArticle(post *discoursePostPay
 		"content":   post.Raw,
 		"tag":       tagValue,
 		"is_active": true,
-	}
-	if err := dh.DB.Model(&article).Updates(updates).Error; err != nil {
-		return nil, false, err
-	}
-	if err := dh.DB.Where("id = ?", article.ID).First(&article).Error; err != nil {
+		"status":    models.ArticleStatusPublished,
+	}
+	if article.PublishAt == nil {
+		updates["publish_at"] = now
+	}
+	previous := article
+	err := dh.DB.Transaction(func(tx *gorm.DB) error {
+		if article.Slug == "" {
+			slug, err := resolveArticleSlug(tx, article.ID, "", strings.TrimSpace(post.TopicTitle))
+			if err != nil {
+				return err
+			}
+			updates["slug"] = slug
+		}
+		if err := tx.Model(&article).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("id = ?", article.ID).First(&article).Error; err != nil {
+			return err
+		}
+		if err := syncArticleTags(tx, article.ID, tags); err != nil {
+			return err
+		}
+		return recordArticleRevision(tx, &previous, &article, models.RevisionSourceDiscourse, editor, 0)
+	})
+	if err != nil {
 		return nil, false, err
 	}
 	return &article, false, nil
@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"blog-server-go/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type FeedHandler struct {
+	BaseHandler
+}
+
+// feedCacheKey feed 缓存的 Redis hash，ArticleUpdateTopic 消费时整体删除
+const feedCacheKey = "feedCache"
+
+const (
+	feedFormatRSS  = "rss"
+	feedFormatAtom = "atom"
+	feedFormatJSON = "json"
+	feedItemLimit  = 20
+	feedCacheTTL   = time.Hour
+)
+
+var feedContentTypes = map[string]string{
+	feedFormatRSS:  "application/rss+xml; charset=utf-8",
+	feedFormatAtom: "application/atom+xml; charset=utf-8",
+	feedFormatJSON: "application/feed+json; charset=utf-8",
+}
+
+// cachedFeed 缓存的 feed 内容
+type cachedFeed struct {
+	Body         string    `json:"body"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// feedEntry 生成 feed 所需的文章信息
+type feedEntry struct {
+	ID        string
+	Title     string
+	URL       string
+	Summary   string
+	Tags      []string
+	Published time.Time
+	Updated   time.Time
+	Created   time.Time
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      atomLink  `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Description string   `xml:"description,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	ID       string      `xml:"id"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Author   *atomAuthor `xml:"author,omitempty"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+type jsonFeed struct {
+	Version     string           `json:"version"`
+	Title       string           `json:"title"`
+	HomePageURL string           `json:"home_page_url,omitempty"`
+	FeedURL     string           `json:"feed_url,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Authors     []jsonFeedAuthor `json:"authors,omitempty"`
+	Language    string           `json:"language,omitempty"`
+	Items       []jsonFeedItem   `json:"items"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonFeedItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	Title         string   `json:"title"`
+	ContentText   string   `json:"content_text"`
+	Summary       string   `json:"summary,omitempty"`
+	DatePublished string   `json:"date_published"`
+	DateModified  string   `json:"date_modified"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// GetRSSFeed RSS 2.0
+func (fh *FeedHandler) GetRSSFeed(c *fiber.Ctx) error {
+	return fh.serveFeed(c, feedFormatRSS)
+}
+
+// GetAtomFeed Atom 1.0
+func (fh *FeedHandler) GetAtomFeed(c *fiber.Ctx) error {
+	return fh.serveFeed(c, feedFormatAtom)
+}
+
+// GetJSONFeed JSON Feed 1.1
+func (fh *FeedHandler) GetJSONFeed(c *fiber.Ctx) error {
+	return fh.serveFeed(c, feedFormatJSON)
+}
+
+// serveFeed 输出 feed，支持 ETag / Last-Modified 条件请求
+func (fh *FeedHandler) serveFeed(c *fiber.Ctx, format string) error {
+	tag, _ := url.PathUnescape(c.Params("tag"))
+	ctx := context.Background()
+	cacheField := format + ":" + tag
+
+	var feed cachedFeed
+	if raw, err := fh.Redis.HGet(ctx, feedCacheKey, cacheField).Result(); err == nil && json.Unmarshal([]byte(raw), &feed) == nil {
+		log.Debugf("Feed cache hit: %s", cacheField)
+	} else {
+		// 标签来自请求路径，不存在的标签直接返回 404，避免任意标签写入缓存
+		if tag != "" {
+			var count int64
+			if err := fh.DB.Model(&models.Tag{}).Where("is_deleted = ? AND name = ?", false, tag).Count(&count).Error; err != nil {
+				log.Errorf("Failed to check feed tag: %v", err)
+				return c.Status(fiber.StatusInternalServerError).SendString("Failed to build feed")
+			}
+			if count == 0 {
+				return c.Status(fiber.StatusNotFound).SendString("Tag not found")
+			}
+		}
+		built, err := fh.buildFeed(ctx, c, format, tag)
+		if err != nil {
+			log.Errorf("Failed to build %s feed: %v", format, err)
+			return c.Status(fiber.StatusInternalServerError).SendString("Failed to build feed")
+		}
+		feed = *built
+		if data, err := json.Marshal(feed); err == nil {
+			fh.Redis.HSet(ctx, feedCacheKey, cacheField, data)
+			fh.Redis.Expire(ctx, feedCacheKey, feedCacheTTL)
+		}
+	}
+
+	c.Set(fiber.HeaderETag, feed.ETag)
+	c.Set(fiber.HeaderCacheControl, "public, max-age=600")
+	if !feed.LastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, feed.LastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if match := c.Get(fiber.HeaderIfNoneMatch); match != "" {
+		if match == feed.ETag || match == "*" {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+	} else if since := c.Get(fiber.HeaderIfModifiedSince); since != "" && !feed.LastModified.IsZero() {
+		if sinceTime, err := http.ParseTime(since); err == nil && !feed.LastModified.Truncate(time.Second).After(sinceTime) {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, feedContentTypes[format])
+	return c.SendString(feed.Body)
+}
+
+// buildFeed 查询已发布文章并生成对应格式的 feed
+func (fh *FeedHandler) buildFeed(ctx context.Context, c *fiber.Ctx, format string, tag string) (*cachedFeed, error) {
+	var articles []models.Article
+	query := publishedArticles(fh.DB.Select("id,title,slug,tag,created_at,updated_at,publish_at"))
+	if tag != "" {
+		query = query.Where("id IN (?)", taggedArticleIDs(fh.DB, tag))
+	}
+	if err := query.Order("publish_at DESC NULLS LAST").Order("created_at DESC").Limit(feedItemLimit).Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	site := loadSiteInfo(ctx, fh.Redis)
+	attachSummaries(fh.DB, articles)
+
+	var lastModified time.Time
+	entries := make([]feedEntry, 0, len(articles))
+	for _, article := range articles {
+		published := article.CreatedAt
+		if article.PublishAt != nil {
+			published = *article.PublishAt
+		}
+		if article.UpdatedAt.After(lastModified) {
+			lastModified = article.UpdatedAt
+		}
+		entries = append(entries, feedEntry{
+			ID:        string(article.ID),
+			Title:     article.Title,
+			URL:       site.articleURL(string(article.ID), article.Slug),
+			Summary:   article.Summary,
+			Tags:      parseTagNames(article.Tag),
+			Published: published,
+			Updated:   article.UpdatedAt,
+			Created:   article.CreatedAt,
+		})
+	}
+
+	title := site.Title
+	if tag != "" {
+		title = site.Title + " - " + tag
+	}
+	// 缓存按格式和标签区分，self 链接只由站点地址和路由生成，不使用请求的 Host 和查询参数
+	selfURL := site.URL + strings.Replace(c.Route().Path, ":tag", url.PathEscape(tag), 1)
+
+	var body []byte
+	var err error
+	switch format {
+	case feedFormatAtom:
+		body, err = renderAtomFeed(site, title, selfURL, lastModified, entries)
+	case feedFormatJSON:
+		body, err = renderJSONFeed(site, title, selfURL, entries)
+	default:
+		body, err = renderRSSFeed(site, title, selfURL, lastModified, entries)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(body)
+	return &cachedFeed{
+		Body:         string(body),
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: lastModified,
+	}, nil
+}
+
+func renderRSSFeed(site siteInfo, title string, selfURL string, lastModified time.Time, entries []feedEntry) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		Channel: rssChannel{
+			Title:       title,
+			Link:        site.URL,
+			Description: site.Description,
+			AtomLink:    atomLink{Href: selfURL, Rel: "self", Type: "application/rss+xml"},
+			Items:       make([]rssItem, 0, len(entries)),
+		},
+	}
+	if feed.Channel.Description == "" {
+		feed.Channel.Description = title
+	}
+	if !lastModified.IsZero() {
+		feed.Channel.LastBuildDate = lastModified.Format(time.RFC1123Z)
+	}
+	for _, entry := range entries {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       entry.Title,
+			Link:        entry.URL,
+			GUID:        rssGUID{IsPermaLink: false, Value: entry.ID},
+			Description: entry.Summary,
+			Categories:  entry.Tags,
+			PubDate:     entry.Published.Format(time.RFC1123Z),
+		})
+	}
+	return marshalXML(feed)
+}
+
+func renderAtomFeed(site siteInfo, title string, selfURL string, lastModified time.Time, entries []feedEntry) ([]byte, error) {
+	if lastModified.IsZero() {
+		lastModified = time.Now()
+	}
+	feed := atomFeed{
+		Title:    title,
+		Subtitle: site.Description,
+		ID:       selfURL,
+		Updated:  lastModified.Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: site.URL, Rel: "alternate", Type: "text/html"},
+			{Href: selfURL, Rel: "self", Type: "application/atom+xml"},
+		},
+		Entries: make([]atomEntry, 0, len(entries)),
+	}
+	// Atom 要求 feed 或每个 entry 都有作者
+	author := site.Author
+	if author == "" {
+		author = site.Title
+	}
+	feed.Author = &atomAuthor{Name: author}
+	for _, entry := range entries {
+		categories := make([]atomCategory, 0, len(entry.Tags))
+		for _, tag := range entry.Tags {
+			categories = append(categories, atomCategory{Term: tag})
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:      entry.Title,
+			ID:         atomEntryID(site, entry),
+			Link:       atomLink{Href: entry.URL, Rel: "alternate", Type: "text/html"},
+			Published:  entry.Published.Format(time.RFC3339),
+			Updated:    entry.Updated.Format(time.RFC3339),
+			Summary:    entry.Summary,
+			Categories: categories,
+		})
+	}
+	return marshalXML(feed)
+}
+
+// atomEntryID 基于文章 ID 的 tag URI（RFC 4151），修改 slug 后保持不变，阅读器不会重复推送
+func atomEntryID(site siteInfo, entry feedEntry) string {
+	host := site.URL
+	if parsed, err := url.Parse(site.URL); err == nil && parsed.Host != "" {
+		host = parsed.Hostname()
+	}
+	return fmt.Sprintf("tag:%s,%s:article/%s", host, entry.Created.Format("2006-01-02"), entry.ID)
+}
+
+func renderJSONFeed(site siteInfo, title string, selfURL string, entries []feedEntry) ([]byte, error) {
+	feed := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       title,
+		HomePageURL: site.URL,
+		FeedURL:     selfURL,
+		Description: site.Description,
+		Language:    "zh-CN",
+		Items:       make([]jsonFeedItem, 0, len(entries)),
+	}
+	if site.Author != "" {
+		feed.Authors = []jsonFeedAuthor{{Name: site.Author}}
+	}
+	for _, entry := range entries {
+		content := entry.Summary
+		if content == "" {
+			content = entry.Title
+		}
+		feed.Items = append(feed.Items, jsonFeedItem{
+			ID:            entry.ID,
+			URL:           entry.URL,
+			Title:         entry.Title,
+			ContentText:   content,
+			Summary:       entry.Summary,
+			DatePublished: entry.Published.Format(time.RFC3339),
+			DateModified:  entry.Updated.Format(time.RFC3339),
+			Tags:          entry.Tags,
+		})
+	}
+	return json.Marshal(feed)
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -3,6 +3,7 @@ package handlers
 import (
 	"blog-server-go/common"
 	"blog-server-go/models"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
@@ -31,15 +32,27 @@ func (fth *FinancialTransactionHandler) CreateTransaction(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(transaction)
 }
 
-// GetTransactions 获取交易记录列表
+// transactionSortKeys 交易记录按交易时间倒序
+var transactionSortKeys = []common.SortKey{{Column: "transaction_time", Desc: true}, {Column: "id", Desc: true}}
+
+// GetTransactions 获取交易记录列表，带 cursor 参数时使用游标分页
 func (fth *FinancialTransactionHandler) GetTransactions(c *fiber.Ctx) error {
+	pageReq, err := common.ParsePageRequest(c, transactionSortKeys, 20, 100)
+	if err != nil {
+		return err
+	}
 	var transactions []models.FinancialTransaction
-	result := fth.DB.Order("transaction_time DESC").Find(&transactions)
+	result := common.ApplyKeyset(fth.DB, transactionSortKeys, pageReq).Find(&transactions)
 	if result.Error != nil {
 		log.Errorf("获取交易记录列表失败: %v", result.Error)
 		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
 	}
-	return c.JSON(transactions)
+	if !pageReq.Enabled {
+		return c.JSON(transactions)
+	}
+	return c.JSON(common.NewPage(transactions, transactionSortKeys, pageReq, func(transaction models.FinancialTransaction) []string {
+		return []string{transaction.TransactionTime.Format(time.RFC3339Nano), string(transaction.ID)}
+	}))
 }
 
 // GetTransactionByID 根据ID获取单个交易记录
@@ -6,20 +6,34 @@ import (
 	"blog-server-go/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"time"
 )
 
 type FriendLinksHandler struct {
 	BaseHandler
 }
 
-// GetFriendLinks retrieves all friend links from the database
+// friendLinkSortKeys friend links are listed oldest first
+var friendLinkSortKeys = []common.SortKey{{Column: "created_at"}, {Column: "id"}}
+
+// GetFriendLinks retrieves all friend links from the database, paged by cursor when the cursor param is present
 func (flh *FriendLinksHandler) GetFriendLinks(c *fiber.Ctx) error {
+	pageReq, err := common.ParsePageRequest(c, friendLinkSortKeys, 20, 100)
+	if err != nil {
+		return err
+	}
 	var friendLinks []models.FriendLink
-	if err := flh.DB.Where("is_deleted", false).Where("is_active", true).Order("created_at").Find(&friendLinks).Error; err != nil {
+	query := flh.DB.Where("is_deleted", false).Where("is_active", true)
+	if err := common.ApplyKeyset(query, friendLinkSortKeys, pageReq).Find(&friendLinks).Error; err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to fetch friend links"})
 	}
 
-	return c.JSON(friendLinks)
+	if !pageReq.Enabled {
+		return c.JSON(friendLinks)
+	}
+	return c.JSON(common.NewPage(friendLinks, friendLinkSortKeys, pageReq, func(link models.FriendLink) []string {
+		return []string{link.CreatedAt.Format(time.RFC3339Nano), string(link.ID)}
+	}))
 }
 
 // SaveFriendLink saves a new friend link to the database
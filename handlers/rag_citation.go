@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// ragCitationMarkerLimit 引用标记的最大字节数，超过时视为普通文本
+	ragCitationMarkerLimit = 24
+	// ragCitationQuoteLimit 引用原文的字节上限
+	ragCitationQuoteLimit = 300
+	// ragCitationInstruction 要求模型在回答中标注引用编号
+	ragCitationInstruction = "引用参考内容时，在对应句子末尾用方括号标注参考内容的编号，例如 [1] 或 [1][3]；只能使用参考内容中给出的编号，不要编造编号，也不要在回答末尾单独列出参考文献。"
+)
+
+// ragCitationMarkerPattern 回答中的引用标记，用于从历史消息中去掉上一轮的编号
+var ragCitationMarkerPattern = regexp.MustCompile(`[\[【]\d+(?:\s*[,，、]\s*\d+)*[\]】]`)
+
+// ragPassage 提供给模型的一段参考内容，回答中以 [Number] 引用
+type ragPassage struct {
+	Number      int
+	ArticleID   models.SnowflakeID
+	Title       string
+	Slug        string
+	HeadingPath string
+	Content     string
+}
+
+// ragPassages 一次问答中编号的参考内容，编号从 1 开始
+type ragPassages []ragPassage
+
+// add 登记一段参考内容并返回编号
+func (passages *ragPassages) add(passage ragPassage) int {
+	passage.Number = len(*passages) + 1
+	*passages = append(*passages, passage)
+	return passage.Number
+}
+
+// lookup 按编号查找参考内容，编号不存在时返回 false
+func (passages ragPassages) lookup(number int) (ragPassage, bool) {
+	if number < 1 || number > len(passages) {
+		return ragPassage{}, false
+	}
+	return passages[number-1], true
+}
+
+// ragCitation citations 事件中的一条引用
+type ragCitation struct {
+	Number      int                `json:"number"`
+	ArticleID   models.SnowflakeID `json:"articleId"`
+	Title       string             `json:"title"`
+	Slug        string             `json:"slug"`
+	HeadingPath string             `json:"headingPath,omitempty"`
+	Quote       string             `json:"quote"`
+}
+
+// citationFilter 识别流式回答中的引用标记并规范化为 [n]
+// 只有所有编号都在参考内容中的标记才视为引用，其余方括号（如 arr[0]、[2024]）原样输出；
+// 代码中的方括号不做处理
+type citationFilter struct {
+	passages ragPassages
+	output   strings.Builder
+	pending  strings.Builder
+	inCode   bool
+	// cited 按首次出现顺序记录引用的编号及引用它的句子
+	cited     []int
+	sentences map[int]string
+}
+
+func newCitationFilter(passages ragPassages) *citationFilter {
+	return &citationFilter{passages: passages, sentences: make(map[int]string)}
+}
+
+// Write 处理一段流式输出，返回可以发送的文本；未闭合的标记会暂存到下一段
+func (f *citationFilter) Write(chunk string) string {
+	start := f.output.Len()
+	for _, r := range chunk {
+		if f.pending.Len() > 0 {
+			if r == ']' || r == '】' {
+				f.pending.WriteRune(r)
+				f.output.WriteString(f.resolve(f.pending.String()))
+				f.pending.Reset()
+				continue
+			}
+			if isCitationMarkerRune(r) && f.pending.Len() < ragCitationMarkerLimit {
+				f.pending.WriteRune(r)
+				continue
+			}
+			// 不是引用标记，原样输出
+			f.output.WriteString(f.pending.String())
+			f.pending.Reset()
+		}
+		if r == '`' {
+			f.inCode = !f.inCode
+		}
+		if !f.inCode && (r == '[' || r == '【') {
+			f.pending.WriteRune(r)
+			continue
+		}
+		f.output.WriteRune(r)
+	}
+	return f.output.String()[start:]
+}
+
+// Flush 输出暂存的未闭合内容
+func (f *citationFilter) Flush() string {
+	rest := f.pending.String()
+	f.pending.Reset()
+	f.output.WriteString(rest)
+	return rest
+}
+
+// Text 过滤后的完整回答
+func (f *citationFilter) Text() string {
+	return f.output.String()
+}
+
+// resolve 解析一个完整的标记；有编号不在参考内容中时视为普通文本原样返回
+func (f *citationFilter) resolve(marker string) string {
+	inner := strings.TrimSuffix(strings.TrimSuffix(marker, "]"), "】")
+	inner = strings.TrimPrefix(strings.TrimPrefix(inner, "["), "【")
+	fields := strings.FieldsFunc(inner, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、' || unicode.IsSpace(r)
+	})
+	if len(fields) == 0 {
+		return marker
+	}
+
+	numbers := make([]int, 0, len(fields))
+	for _, field := range fields {
+		number, err := strconv.Atoi(field)
+		if err != nil {
+			return marker
+		}
+		if _, ok := f.passages.lookup(number); !ok {
+			return marker
+		}
+		numbers = append(numbers, number)
+	}
+
+	var result strings.Builder
+	for _, number := range numbers {
+		if _, ok := f.sentences[number]; !ok {
+			f.cited = append(f.cited, number)
+			f.sentences[number] = citingSentence(f.output.String())
+		}
+		result.WriteString("[" + strconv.Itoa(number) + "]")
+	}
+	return result.String()
+}
+
+// Citations 回答中实际引用的参考内容，按首次出现顺序排列
+func (f *citationFilter) Citations() []ragCitation {
+	citations := make([]ragCitation, 0, len(f.cited))
+	for _, number := range f.cited {
+		passage, _ := f.passages.lookup(number)
+		citations = append(citations, ragCitation{
+			Number:      number,
+			ArticleID:   passage.ArticleID,
+			Title:       passage.Title,
+			Slug:        passage.Slug,
+			HeadingPath: passage.HeadingPath,
+			Quote:       quotePassage(passage.Content, f.sentences[number]),
+		})
+	}
+	return citations
+}
+
+func isCitationMarkerRune(r rune) bool {
+	return (r >= '0' && r <= '9') || r == ',' || r == '，' || r == '、' || r == ' '
+}
+
+func isSentenceEnd(r rune) bool {
+	return strings.ContainsRune("。！？!?；;\n", r)
+}
+
+// citingSentence 标记前的最后一句话，标记紧跟在句号之后时取句号之前的一句
+func citingSentence(text string) string {
+	text = strings.TrimRightFunc(text, func(r rune) bool {
+		return unicode.IsSpace(r) || isSentenceEnd(r) || r == '.'
+	})
+	start := strings.LastIndexFunc(text, isSentenceEnd)
+	if start >= 0 {
+		_, size := utf8.DecodeRuneInString(text[start:])
+		text = text[start+size:]
+	}
+	return strings.TrimSpace(ragCitationMarkerPattern.ReplaceAllString(text, ""))
+}
+
+// splitSentences 按中英文句末标点切分段落
+func splitSentences(text string) []string {
+	var sentences []string
+	var current strings.Builder
+	runes := []rune(text)
+	for i, r := range runes {
+		current.WriteRune(r)
+		end := isSentenceEnd(r) || (r == '.' && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])))
+		if end {
+			if sentence := strings.TrimSpace(current.String()); sentence != "" {
+				sentences = append(sentences, sentence)
+			}
+			current.Reset()
+		}
+	}
+	if sentence := strings.TrimSpace(current.String()); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// runeBigrams 去掉空白和标点后的字符二元组，中英文都适用
+func runeBigrams(text string) map[string]bool {
+	var runes []rune
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, r)
+		}
+	}
+	bigrams := make(map[string]bool, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		bigrams[string(runes[i:i+2])] = true
+	}
+	return bigrams
+}
+
+// quotePassage 从参考内容中选出与引用句重合最多的一句作为引用原文，没有重合时取开头
+func quotePassage(content string, sentence string) string {
+	candidates := splitSentences(content)
+	if len(candidates) == 0 {
+		return ""
+	}
+	target := runeBigrams(sentence)
+	best, bestScore := candidates[0], 0
+	for _, candidate := range candidates {
+		score := 0
+		for bigram := range runeBigrams(candidate) {
+			if target[bigram] {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	return common.TruncateUTF8(best, ragCitationQuoteLimit)
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testPassages() ragPassages {
+	var passages ragPassages
+	passages.add(ragPassage{ArticleID: "1", Title: "Go 并发", Content: "goroutine 是轻量级线程。channel 用于通信。"})
+	passages.add(ragPassage{ArticleID: "2", Title: "Redis 缓存", Content: "缓存需要设置过期时间。"})
+	passages.add(ragPassage{ArticleID: "3", Title: "Postgres 索引", Content: "索引可以加速查询。"})
+	return passages
+}
+
+func TestCitationFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+		cited  []int
+	}{
+		{
+			name:   "marker in one chunk",
+			chunks: []string{"goroutine 很轻量[1]。"},
+			want:   "goroutine 很轻量[1]。",
+			cited:  []int{1},
+		},
+		{
+			name:   "marker split across chunks",
+			chunks: []string{"goroutine 很轻量[", "1", "]。"},
+			want:   "goroutine 很轻量[1]。",
+			cited:  []int{1},
+		},
+		{
+			name:   "multiple numbers split across chunks",
+			chunks: []string{"缓存要过期[2, ", "3]"},
+			want:   "缓存要过期[2][3]",
+			cited:  []int{2, 3},
+		},
+		{
+			name:   "fullwidth brackets and separators",
+			chunks: []string{"见【1，", "2】。"},
+			want:   "见[1][2]。",
+			cited:  []int{1, 2},
+		},
+		{
+			name:   "first occurrence order",
+			chunks: []string{"a[3]b", "[1][3]"},
+			want:   "a[3]b[1][3]",
+			cited:  []int{3, 1},
+		},
+		{
+			name:   "index not in sources",
+			chunks: []string{"arr[", "0] = 1"},
+			want:   "arr[0] = 1",
+		},
+		{
+			name:   "year in brackets",
+			chunks: []string{"发布于 [20", "24] 年"},
+			want:   "发布于 [2024] 年",
+		},
+		{
+			name:   "mixed known and unknown numbers",
+			chunks: []string{"[1, ", "9]"},
+			want:   "[1, 9]",
+		},
+		{
+			name:   "inline code split across chunks",
+			chunks: []string{"使用 `x[", "1]` 取值"},
+			want:   "使用 `x[1]` 取值",
+		},
+		{
+			name:   "markdown link",
+			chunks: []string{"[文", "档](https://example.com)"},
+			want:   "[文档](https://example.com)",
+		},
+		{
+			name:   "unterminated marker flushed at end",
+			chunks: []string{"见 [", "1"},
+			want:   "见 [1",
+		},
+		{
+			name:   "marker longer than limit",
+			chunks: []string{"[" + strings.Repeat("1", ragCitationMarkerLimit), "]"},
+			want:   "[" + strings.Repeat("1", ragCitationMarkerLimit) + "]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := newCitationFilter(testPassages())
+			var streamed strings.Builder
+			for _, chunk := range tt.chunks {
+				streamed.WriteString(filter.Write(chunk))
+			}
+			streamed.WriteString(filter.Flush())
+
+			if got := streamed.String(); got != tt.want {
+				t.Errorf("streamed = %q, want %q", got, tt.want)
+			}
+			if got := filter.Text(); got != tt.want {
+				t.Errorf("Text() = %q, want %q", got, tt.want)
+			}
+			var cited []int
+			for _, citation := range filter.Citations() {
+				cited = append(cited, citation.Number)
+			}
+			if !slices.Equal(cited, tt.cited) {
+				t.Errorf("cited = %v, want %v", cited, tt.cited)
+			}
+		})
+	}
+}
+
+func TestCitationFilterHoldsPendingMarker(t *testing.T) {
+	filter := newCitationFilter(testPassages())
+	if got := filter.Write("channel 用于通信[1"); got != "channel 用于通信" {
+		t.Fatalf("Write = %q, want text before the unfinished marker", got)
+	}
+	if got := filter.Write("]。"); got != "[1]。" {
+		t.Fatalf("Write = %q, want resolved marker", got)
+	}
+
+	citations := filter.Citations()
+	if len(citations) != 1 || citations[0].Quote != "channel 用于通信。" {
+		t.Fatalf("Citations() = %+v, want quote of the cited sentence", citations)
+	}
+}
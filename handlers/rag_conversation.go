@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// ragConversationKeyPrefix 会话内容，key 为 ragConversation:<id>
+	ragConversationKeyPrefix = "ragConversation:"
+	// ragVisitorConversationsPrefix 访客的会话列表（zset，score 为最后更新时间），key 为 ragConversations:<visitor>
+	ragVisitorConversationsPrefix = "ragConversations:"
+	// ragConversationTTL 会话在最后一次提问后保留的时间
+	ragConversationTTL = 7 * 24 * time.Hour
+	// ragConversationMaxMessages 每个会话保留的消息数，超出时丢弃最早的消息
+	ragConversationMaxMessages = 40
+	// ragHistoryMessages 查询扩展和生成回答时带上的历史消息数
+	ragHistoryMessages = 6
+	// ragHistoryMessageLimit 历史消息的字节上限，避免长回答挤占参考内容
+	ragHistoryMessageLimit    = 1500
+	ragConversationTitleLimit = 60
+	// ragVisitorCookie 未登录访客的标识
+	ragVisitorCookie = "blog_visitor"
+)
+
+// RAGMessage 会话中的一条消息
+type RAGMessage struct {
+	Role      string        `json:"role"` // user / assistant
+	Content   string        `json:"content"`
+	Sources   []ragSource   `json:"sources,omitempty"`
+	Citations []ragCitation `json:"citations,omitempty"`
+	CreatedAt time.Time     `json:"createdAt"`
+}
+
+// RAGConversation 问答会话
+type RAGConversation struct {
+	ID        string       `json:"id"`
+	VisitorID string       `json:"-"`
+	Title     string       `json:"title"`
+	Messages  []RAGMessage `json:"messages"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// RAGConversationSummary 会话列表项
+type RAGConversationSummary struct {
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	MessageCount int       `json:"messageCount"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// ragConversationRecord Redis 中保存的会话，包含不对外返回的访客标识
+type ragConversationRecord struct {
+	RAGConversation
+	VisitorID string `json:"visitorId"`
+}
+
+var errRAGConversationNotFound = errors.New("conversation not found")
+
+// ragVisitorID 登录用户使用用户 ID，未登录访客使用 cookie 中的随机标识
+// create 为 true 时为没有标识的访客生成并写入 cookie
+func ragVisitorID(c *fiber.Ctx, create bool) string {
+	if userID, _ := c.Locals("userId").(string); userID != "" {
+		return "user:" + userID
+	}
+	if visitor := c.Cookies(ragVisitorCookie); visitor != "" {
+		return "visitor:" + visitor
+	}
+	if !create {
+		return ""
+	}
+	visitor := newRAGConversationID()
+	c.Cookie(&fiber.Cookie{
+		Name:     ragVisitorCookie,
+		Value:    visitor,
+		Path:     "/",
+		MaxAge:   365 * 24 * 3600,
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return "visitor:" + visitor
+}
+
+func newRAGConversationID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return hex.EncodeToString([]byte(time.Now().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(b)
+}
+
+// loadRAGConversation 读取访客的会话，不存在、已过期或属于其他访客时返回 errRAGConversationNotFound
+func loadRAGConversation(ctx context.Context, rdb *redis.Client, visitorID string, id string) (*RAGConversation, error) {
+	if visitorID == "" || id == "" {
+		return nil, errRAGConversationNotFound
+	}
+	raw, err := rdb.Get(ctx, ragConversationKeyPrefix+id).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, errRAGConversationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var record ragConversationRecord
+	if err := json.Unmarshal([]byte(raw), &record); err != nil {
+		return nil, err
+	}
+	if record.VisitorID != visitorID {
+		return nil, errRAGConversationNotFound
+	}
+	conversation := record.RAGConversation
+	conversation.VisitorID = record.VisitorID
+	return &conversation, nil
+}
+
+// saveRAGConversation 保存会话并刷新过期时间
+func saveRAGConversation(ctx context.Context, rdb *redis.Client, conversation *RAGConversation) error {
+	if len(conversation.Messages) > ragConversationMaxMessages {
+		conversation.Messages = conversation.Messages[len(conversation.Messages)-ragConversationMaxMessages:]
+	}
+	data, err := json.Marshal(ragConversationRecord{RAGConversation: *conversation, VisitorID: conversation.VisitorID})
+	if err != nil {
+		return err
+	}
+	listKey := ragVisitorConversationsPrefix + conversation.VisitorID
+	_, err = rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, ragConversationKeyPrefix+conversation.ID, data, ragConversationTTL)
+		pipe.ZAdd(ctx, listKey, redis.Z{Score: float64(conversation.UpdatedAt.Unix()), Member: conversation.ID})
+		// 清理列表中已经过期的会话
+		pipe.ZRemRangeByScore(ctx, listKey, "-inf", "("+strconv.FormatInt(time.Now().Add(-ragConversationTTL).Unix(), 10))
+		pipe.Expire(ctx, listKey, ragConversationTTL)
+		return nil
+	})
+	return err
+}
+
+// appendRAGTurn 追加一轮问答并保存
+func appendRAGTurn(rdb *redis.Client, conversation *RAGConversation, question string, answer string, sources []ragSource, citations []ragCitation) {
+	now := time.Now()
+	conversation.Messages = append(conversation.Messages,
+		RAGMessage{Role: string(schema.User), Content: question, CreatedAt: now},
+		RAGMessage{Role: string(schema.Assistant), Content: answer, Sources: sources, Citations: citations, CreatedAt: now},
+	)
+	conversation.UpdatedAt = now
+	if err := saveRAGConversation(context.Background(), rdb, conversation); err != nil {
+		log.Errorf("Failed to save RAG conversation %s: %v", conversation.ID, err)
+	}
+}
+
+// historyMessages 最近几轮对话，转换为 LLM 消息
+// 之前回答中的引用编号对应的是之前的参考内容，去掉以免与本轮编号混淆
+func (conversation *RAGConversation) historyMessages() []*schema.Message {
+	if conversation == nil {
+		return nil
+	}
+	history := conversation.Messages
+	if len(history) > ragHistoryMessages {
+		history = history[len(history)-ragHistoryMessages:]
+	}
+	messages := make([]*schema.Message, 0, len(history))
+	for _, message := range history {
+		content := common.TruncateUTF8(message.Content, ragHistoryMessageLimit)
+		if message.Role == string(schema.Assistant) {
+			content = ragCitationMarkerPattern.ReplaceAllString(content, "")
+			messages = append(messages, schema.AssistantMessage(content, nil))
+		} else {
+			messages = append(messages, schema.UserMessage(content))
+		}
+	}
+	return messages
+}
+
+// GetRAGConversations 当前访客的会话列表，按最后更新时间倒序
+func (ah *ArticleHandler) GetRAGConversations(c *fiber.Ctx) error {
+	visitorID := ragVisitorID(c, false)
+	conversations := make([]RAGConversationSummary, 0)
+	if visitorID == "" {
+		return c.JSON(conversations)
+	}
+
+	ctx := context.Background()
+	listKey := ragVisitorConversationsPrefix + visitorID
+	ids, err := ah.Redis.ZRevRange(ctx, listKey, 0, -1).Result()
+	if err != nil {
+		log.Errorf("Failed to list RAG conversations: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	for _, id := range ids {
+		conversation, err := loadRAGConversation(ctx, ah.Redis, visitorID, id)
+		if errors.Is(err, errRAGConversationNotFound) {
+			ah.Redis.ZRem(ctx, listKey, id)
+			continue
+		}
+		if err != nil {
+			log.Errorf("Failed to load RAG conversation %s: %v", id, err)
+			continue
+		}
+		conversations = append(conversations, RAGConversationSummary{
+			ID:           conversation.ID,
+			Title:        conversation.Title,
+			MessageCount: len(conversation.Messages),
+			CreatedAt:    conversation.CreatedAt,
+			UpdatedAt:    conversation.UpdatedAt,
+		})
+	}
+	return c.JSON(conversations)
+}
+
+// GetRAGConversation 获取会话的完整消息
+func (ah *ArticleHandler) GetRAGConversation(c *fiber.Ctx) error {
+	conversation, err := loadRAGConversation(context.Background(), ah.Redis, ragVisitorID(c, false), c.Params("id"))
+	if errors.Is(err, errRAGConversationNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Conversation not found"})
+	}
+	if err != nil {
+		log.Errorf("Failed to load RAG conversation: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	return c.JSON(conversation)
+}
+
+// DeleteRAGConversation 删除会话
+func (ah *ArticleHandler) DeleteRAGConversation(c *fiber.Ctx) error {
+	ctx := context.Background()
+	visitorID := ragVisitorID(c, false)
+	conversation, err := loadRAGConversation(ctx, ah.Redis, visitorID, c.Params("id"))
+	if errors.Is(err, errRAGConversationNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "Conversation not found"})
+	}
+	if err != nil {
+		log.Errorf("Failed to load RAG conversation: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	_, err = ah.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, ragConversationKeyPrefix+conversation.ID)
+		pipe.ZRem(ctx, ragVisitorConversationsPrefix+visitorID, conversation.ID)
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Failed to delete RAG conversation: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,401 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"blog-server-go/services"
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// searchKeywordsKey 全部时间的搜索次数
+	searchKeywordsKey = "searchKeywords"
+	// searchKeywordsBucketPrefix 按小时分桶的搜索次数，key 为 searchKeywords:2006010215
+	searchKeywordsBucketPrefix = "searchKeywords:"
+	searchKeywordsBucketLayout = "2006010215"
+	// searchTrendingCachePrefix 热搜合并结果的短期缓存，key 为 searchTrending:<hours>
+	searchTrendingCachePrefix = "searchTrending:"
+	// searchSuggestionsKey 有结果的历史搜索词，score 均为 0，按字典序做前缀查询
+	searchSuggestionsKey = "searchSuggestions"
+	// searchZeroResultsKey 无结果的搜索词及次数
+	searchZeroResultsKey = "searchZeroResults"
+	// searchZeroResultsSeenKey 无结果搜索词最近一次被搜索的时间（unix 秒）
+	searchZeroResultsSeenKey = "searchZeroResultsLastSeen"
+
+	searchKeywordMaxLength     = 64
+	searchTrendingDefaultHours = 24
+	searchTrendingMaxHours     = 7 * 24
+	searchTrendingCacheTTL     = time.Minute
+	searchTrendingDefaultLimit = 10
+	searchTrendingMaxLimit     = 50
+	searchSuggestDefaultLimit  = 8
+	searchSuggestMaxLimit      = 20
+	searchZeroResultsLimit     = 50
+	searchZeroResultsMaxLimit  = 200
+)
+
+// SearchHandler 搜索热词、自动补全和无结果搜索统计
+type SearchHandler struct {
+	BaseHandler
+}
+
+// TrendingKeyword 热搜词
+type TrendingKeyword struct {
+	Keyword string  `json:"keyword"`
+	Count   float64 `json:"count"`
+}
+
+// SearchSuggestion 搜索补全项，type 为 title、tag 或 query
+type SearchSuggestion struct {
+	Text string             `json:"text"`
+	Type string             `json:"type"`
+	ID   models.SnowflakeID `json:"id,omitempty"`
+	Slug string             `json:"slug,omitempty"`
+}
+
+// ZeroResultKeyword 没有搜索结果的搜索词
+type ZeroResultKeyword struct {
+	Keyword        string     `json:"keyword"`
+	Count          float64    `json:"count"`
+	LastSearchedAt *time.Time `json:"lastSearchedAt"`
+}
+
+// normalizeSearchKeyword 统一大小写和空白，过长的搜索词不参与统计
+func normalizeSearchKeyword(keyword string) (string, bool) {
+	keyword = strings.ToLower(strings.Join(strings.Fields(keyword), " "))
+	if keyword == "" || utf8.RuneCountInString(keyword) > searchKeywordMaxLength {
+		return "", false
+	}
+	return keyword, true
+}
+
+// searchKeywordsBucket 某一时刻所在的小时分桶
+func searchKeywordsBucket(t time.Time) string {
+	return searchKeywordsBucketPrefix + t.UTC().Format(searchKeywordsBucketLayout)
+}
+
+// recordSearchKeyword 累计搜索次数，同时写入当前小时的分桶，分桶保留到热搜窗口之外自动过期
+func recordSearchKeyword(ctx context.Context, rdb *redis.Client, keyword string) {
+	keyword, ok := normalizeSearchKeyword(keyword)
+	if !ok {
+		return
+	}
+	bucket := searchKeywordsBucket(time.Now())
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZIncrBy(ctx, searchKeywordsKey, 1, keyword)
+		pipe.ZIncrBy(ctx, bucket, 1, keyword)
+		pipe.Expire(ctx, bucket, (searchTrendingMaxHours+1)*time.Hour)
+		return nil
+	})
+	common.HandleError(err, "Error incrementing keyword score:")
+}
+
+// recordSearchResult 记录搜索结果：有结果的搜索词加入补全候选，无结果的计入无结果统计
+func recordSearchResult(ctx context.Context, rdb *redis.Client, keyword string, resultCount int) {
+	keyword, ok := normalizeSearchKeyword(keyword)
+	if !ok {
+		return
+	}
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if resultCount > 0 {
+			pipe.ZAdd(ctx, searchSuggestionsKey, redis.Z{Member: keyword})
+			pipe.ZRem(ctx, searchZeroResultsKey, keyword)
+			pipe.HDel(ctx, searchZeroResultsSeenKey, keyword)
+			return nil
+		}
+		pipe.ZRem(ctx, searchSuggestionsKey, keyword)
+		pipe.ZIncrBy(ctx, searchZeroResultsKey, 1, keyword)
+		pipe.HSet(ctx, searchZeroResultsSeenKey, keyword, time.Now().Unix())
+		return nil
+	})
+	common.HandleError(err, "Error recording search result:")
+}
+
+// escapeLikePattern 转义 LIKE 通配符
+func escapeLikePattern(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// parseLimitQuery 解析 limit 参数，超过上限时取上限
+func parseLimitQuery(c *fiber.Ctx, defaultLimit int, maxLimit int) (int, error) {
+	limitStr := c.Query("limit")
+	if limitStr == "" {
+		return defaultLimit, nil
+	}
+	value, err := strconv.Atoi(limitStr)
+	if err != nil || value <= 0 {
+		return 0, &common.BusinessException{Code: 5000, Message: "Invalid limit value"}
+	}
+	return min(value, maxLimit), nil
+}
+
+// GetTrendingKeywords 最近 hours 小时（默认 24，最多 168）内搜索次数最多的关键词
+func (sh *SearchHandler) GetTrendingKeywords(c *fiber.Ctx) error {
+	limit, err := parseLimitQuery(c, searchTrendingDefaultLimit, searchTrendingMaxLimit)
+	if err != nil {
+		return err
+	}
+	hours := searchTrendingDefaultHours
+	if hoursStr := c.Query("hours"); hoursStr != "" {
+		value, err := strconv.Atoi(hoursStr)
+		if err != nil || value <= 0 || value > searchTrendingMaxHours {
+			return &common.BusinessException{Code: 5000, Message: "hours must be between 1 and 168"}
+		}
+		hours = value
+	}
+
+	ctx := context.Background()
+	cacheKey := searchTrendingCachePrefix + strconv.Itoa(hours)
+	// 合并结果为空时 ZUNIONSTORE 不会创建 key，用标记 key 记录已合并，空结果同样缓存
+	builtKey := cacheKey + ":built"
+	exists, err := sh.Redis.Exists(ctx, builtKey).Result()
+	if err != nil {
+		log.Errorf("Failed to read trending cache: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	if exists == 0 {
+		// 合并窗口内的小时分桶，结果缓存一分钟
+		now := time.Now()
+		buckets := make([]string, 0, hours)
+		for i := 0; i < hours; i++ {
+			buckets = append(buckets, searchKeywordsBucket(now.Add(-time.Duration(i)*time.Hour)))
+		}
+		_, err := sh.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.ZUnionStore(ctx, cacheKey, &redis.ZStore{Keys: buckets, Aggregate: "SUM"})
+			pipe.Expire(ctx, cacheKey, searchTrendingCacheTTL)
+			pipe.Set(ctx, builtKey, 1, searchTrendingCacheTTL)
+			return nil
+		})
+		if err != nil {
+			log.Errorf("Failed to aggregate trending keywords: %v", err)
+			return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+		}
+	}
+
+	entries, err := sh.Redis.ZRevRangeWithScores(ctx, cacheKey, 0, int64(limit-1)).Result()
+	if err != nil {
+		log.Errorf("Failed to get trending keywords: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	keywords := make([]TrendingKeyword, 0, len(entries))
+	for _, entry := range entries {
+		keywords = append(keywords, TrendingKeyword{Keyword: entry.Member.(string), Count: entry.Score})
+	}
+	return c.JSON(keywords)
+}
+
+// GetSuggestions 根据输入前缀补全：文章标题、标签、热门的历史搜索词，依次排列并去重
+func (sh *SearchHandler) GetSuggestions(c *fiber.Ctx) error {
+	limit, err := parseLimitQuery(c, searchSuggestDefaultLimit, searchSuggestMaxLimit)
+	if err != nil {
+		return err
+	}
+	prefix, ok := normalizeSearchKeyword(c.Query("q"))
+	if !ok {
+		return c.JSON([]SearchSuggestion{})
+	}
+
+	suggestions := make([]SearchSuggestion, 0, limit)
+	seen := make(map[string]bool)
+	add := func(suggestion SearchSuggestion) {
+		key := strings.ToLower(suggestion.Text)
+		if seen[key] || len(suggestions) >= limit {
+			return
+		}
+		seen[key] = true
+		suggestions = append(suggestions, suggestion)
+	}
+
+	// 标题最多占一半、标签最多占四分之一，其余留给历史搜索词
+	titleLimit, tagLimit := (limit+1)/2, (limit+3)/4
+
+	// 标题以输入开头的排在前面，其次是包含输入的标题，同类按阅读量排序
+	pattern := escapeLikePattern(prefix)
+	var articles []models.Article
+	result := publishedArticles(sh.DB.Select("id,title,slug")).
+		Where("title ILIKE ?", "%"+pattern+"%").
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: "title ILIKE ? DESC", Vars: []interface{}{pattern + "%"}}}).
+		Order("view_count DESC").
+		Limit(titleLimit).
+		Find(&articles)
+	if result.Error != nil {
+		log.Errorf("Failed to suggest article titles: %v", result.Error)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	for _, article := range articles {
+		add(SearchSuggestion{Text: article.Title, Type: "title", ID: article.ID, Slug: article.Slug})
+	}
+
+	var tags []string
+	result = sh.DB.Model(&models.Tag{}).
+		Where("is_deleted = ? AND name ILIKE ?", false, pattern+"%").
+		Order("name").
+		Limit(tagLimit).
+		Pluck("name", &tags)
+	if result.Error != nil {
+		log.Errorf("Failed to suggest tags: %v", result.Error)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	for _, tag := range tags {
+		add(SearchSuggestion{Text: tag, Type: "tag"})
+	}
+
+	for _, keyword := range sh.popularQueriesWithPrefix(prefix, limit-len(suggestions)) {
+		add(SearchSuggestion{Text: keyword, Type: "query"})
+	}
+	return c.JSON(suggestions)
+}
+
+// popularQueriesWithPrefix 以 prefix 开头且有结果的历史搜索词，按累计搜索次数排序
+func (sh *SearchHandler) popularQueriesWithPrefix(prefix string, limit int) []string {
+	ctx := context.Background()
+	candidates, err := sh.Redis.ZRangeByLex(ctx, searchSuggestionsKey, &redis.ZRangeBy{
+		Min:   "[" + prefix,
+		Max:   "[" + prefix + "\xff",
+		Count: 100,
+	}).Result()
+	if err != nil {
+		log.Errorf("Failed to suggest past queries: %v", err)
+		return nil
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	scores, err := sh.Redis.ZMScore(ctx, searchKeywordsKey, candidates...).Result()
+	if err != nil {
+		log.Errorf("Failed to get past query scores: %v", err)
+		return nil
+	}
+	entries := make([]TrendingKeyword, 0, len(candidates))
+	for i, candidate := range candidates {
+		entries = append(entries, TrendingKeyword{Keyword: candidate, Count: scores[i]})
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Count > entries[j].Count
+	})
+	keywords := make([]string, 0, limit)
+	for _, entry := range entries {
+		if len(keywords) >= limit {
+			break
+		}
+		keywords = append(keywords, entry.Keyword)
+	}
+	return keywords
+}
+
+// GetZeroResultKeywords 没有搜索结果的搜索词，按次数排序，用于发现缺少的内容
+func (sh *SearchHandler) GetZeroResultKeywords(c *fiber.Ctx) error {
+	limit, err := parseLimitQuery(c, searchZeroResultsLimit, searchZeroResultsMaxLimit)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	entries, err := sh.Redis.ZRevRangeWithScores(ctx, searchZeroResultsKey, 0, int64(limit-1)).Result()
+	if err != nil {
+		log.Errorf("Failed to get zero-result keywords: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	keywords := make([]ZeroResultKeyword, 0, len(entries))
+	if len(entries) == 0 {
+		return c.JSON(keywords)
+	}
+
+	members := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		members = append(members, entry.Member.(string))
+	}
+	lastSeen, err := sh.Redis.HMGet(ctx, searchZeroResultsSeenKey, members...).Result()
+	if err != nil {
+		log.Errorf("Failed to get zero-result timestamps: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	for i, entry := range entries {
+		keyword := ZeroResultKeyword{Keyword: members[i], Count: entry.Score}
+		if raw, ok := lastSeen[i].(string); ok {
+			if unix, err := strconv.ParseInt(raw, 10, 64); err == nil {
+				t := time.Unix(unix, 0)
+				keyword.LastSearchedAt = &t
+			}
+		}
+		keywords = append(keywords, keyword)
+	}
+	return c.JSON(keywords)
+}
+
+// DeleteZeroResultKeyword 补充内容后从无结果列表中移除搜索词
+func (sh *SearchHandler) DeleteZeroResultKeyword(c *fiber.Ctx) error {
+	keyword, ok := normalizeSearchKeyword(c.Query("keyword"))
+	if !ok {
+		return &common.BusinessException{Code: 5000, Message: "keyword is required"}
+	}
+	ctx := context.Background()
+	_, err := sh.Redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, searchZeroResultsKey, keyword)
+		pipe.HDel(ctx, searchZeroResultsSeenKey, keyword)
+		return nil
+	})
+	if err != nil {
+		log.Errorf("Failed to delete zero-result keyword: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetSearchSettings 获取管理员维护的同义词、停用词和排序规则
+func (sh *SearchHandler) GetSearchSettings(c *fiber.Ctx) error {
+	settings, err := services.LoadSearchSettings(context.Background(), sh.Redis)
+	if err != nil {
+		log.Errorf("Failed to load search settings: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	return c.JSON(settings)
+}
+
+// UpdateSearchSettings 保存搜索设置并立即应用到线上索引，请求中未出现的字段保持不变
+// 设置单独保存在 searchSettings 中，重建索引时会重新应用
+func (sh *SearchHandler) UpdateSearchSettings(c *fiber.Ctx) error {
+	var input services.SearchSettings
+	if err := c.BodyParser(&input); err != nil {
+		return common.NewBusinessException(5000, "Invalid request body")
+	}
+
+	ctx := context.Background()
+	settings, err := services.LoadSearchSettings(ctx, sh.Redis)
+	if err != nil {
+		log.Errorf("Failed to load search settings: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	if input.Synonyms != nil {
+		settings.Synonyms = input.Synonyms
+	}
+	if input.StopWords != nil {
+		settings.StopWords = input.StopWords
+	}
+	if input.RankingRules != nil {
+		settings.RankingRules = input.RankingRules
+	}
+	if _, err := settings.Normalize(); err != nil {
+		return common.NewBusinessException(5000, err.Error())
+	}
+	settings, err = services.SaveSearchSettings(ctx, sh.Redis, settings)
+	if err != nil {
+		log.Errorf("Failed to save search settings: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	if err := services.EnsureArticleIndex(sh.Meili, services.ArticleSearchIndex, settings); err != nil {
+		log.Errorf("Failed to apply search settings to Meilisearch: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Search settings saved but failed to apply to the index"})
+	}
+	return c.JSON(settings)
+}
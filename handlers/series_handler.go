@@ -0,0 +1,393 @@
+package handlers
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/kafka"
+	"blog-server-go/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type SeriesHandler struct {
+	BaseHandler
+}
+
+// seriesInput 新建/更新系列的请求体，articleIds 的顺序即系列顺序
+type seriesInput struct {
+	Title       string               `json:"title"`
+	Slug        string               `json:"slug"`
+	Description string               `json:"description"`
+	ArticleIDs  []models.SnowflakeID `json:"articleIds"`
+}
+
+// SeriesWithCount 带文章数量的系列
+type SeriesWithCount struct {
+	ID           models.SnowflakeID `json:"id"`
+	Title        string             `json:"title"`
+	Slug         string             `json:"slug"`
+	Description  string             `json:"description"`
+	ArticleCount int64              `json:"articleCount"`
+}
+
+// seriesMember 系列中的已发布文章
+type seriesMember struct {
+	ID        models.SnowflakeID `json:"id"`
+	Title     string             `json:"title"`
+	Slug      string             `json:"slug"`
+	Position  int                `json:"position"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+// publishedSeriesMembers 按顺序获取系列中的已发布文章
+func publishedSeriesMembers(db *gorm.DB, seriesID models.SnowflakeID) ([]seriesMember, error) {
+	var members []seriesMember
+	err := db.Table("series_article").
+		Select("article.id, article.title, article.slug, series_article.position, article.created_at").
+		Joins("JOIN article ON article.id = series_article.article_id").
+		Where("series_article.series_id = ?", seriesID).
+		Where("article.is_deleted = false AND article.status = ?", models.ArticleStatusPublished).
+		Order("series_article.position").
+		Scan(&members).Error
+	return members, err
+}
+
+// loadSeriesNavigation 获取文章所在系列以及上一篇/下一篇，不属于任何系列时返回 nil
+func loadSeriesNavigation(db *gorm.DB, articleID models.SnowflakeID) (*models.SeriesNavigation, error) {
+	var membership models.SeriesArticle
+	result := db.Where("article_id = ?", articleID).Limit(1).Find(&membership)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return nil, result.Error
+	}
+
+	var series models.Series
+	if err := db.Where("is_deleted", false).Take(&series, "id = ?", membership.SeriesID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	members, err := publishedSeriesMembers(db, series.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	nav := &models.SeriesNavigation{ID: series.ID, Title: series.Title, Slug: series.Slug, Total: len(members)}
+	for i, member := range members {
+		if member.ID != articleID {
+			continue
+		}
+		nav.Position = i + 1
+		if i > 0 {
+			prev := members[i-1]
+			nav.Prev = &models.SeriesArticleRef{ID: prev.ID, Title: prev.Title, Slug: prev.Slug}
+		}
+		if i < len(members)-1 {
+			next := members[i+1]
+			nav.Next = &models.SeriesArticleRef{ID: next.ID, Title: next.Title, Slug: next.Slug}
+		}
+		break
+	}
+	return nav, nil
+}
+
+// resolveSeriesSlug 生成唯一的系列 slug
+func resolveSeriesSlug(tx *gorm.DB, seriesID models.SnowflakeID, requested string, title string) (string, error) {
+	base := common.Slugify(requested)
+	if base == "" {
+		base = common.Slugify(title)
+	}
+	if base == "" {
+		base = "series-" + string(seriesID)
+	}
+	candidate := base
+	for n := 2; ; n++ {
+		var count int64
+		if err := tx.Model(&models.Series{}).Where("slug = ? AND id <> ?", candidate, seriesID).Count(&count).Error; err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// replaceSeriesMembers 重写系列成员及顺序，已在其他系列中的文章会被移入本系列
+// 返回变更前后涉及的所有文章 ID，以及被移走文章的原系列 ID
+func replaceSeriesMembers(tx *gorm.DB, seriesID models.SnowflakeID, articleIDs []models.SnowflakeID) ([]models.SnowflakeID, []models.SnowflakeID, error) {
+	var affected []models.SnowflakeID
+	if err := tx.Model(&models.SeriesArticle{}).Where("series_id = ?", seriesID).Pluck("article_id", &affected).Error; err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Where("series_id = ?", seriesID).Delete(&models.SeriesArticle{}).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(articleIDs) == 0 {
+		return affected, nil, nil
+	}
+
+	var existing int64
+	if err := tx.Model(&models.Article{}).Where("id IN ?", articleIDs).Where("is_deleted", false).Count(&existing).Error; err != nil {
+		return nil, nil, err
+	}
+	if int(existing) != len(articleIDs) {
+		return nil, nil, &common.BusinessException{Code: 5000, Message: "Series contains unknown or duplicated articles"}
+	}
+
+	// 原系列的页面和剩余成员的上一篇/下一篇也会变化
+	var previousSeries []models.SnowflakeID
+	if err := tx.Model(&models.SeriesArticle{}).Where("article_id IN ?", articleIDs).Distinct().Pluck("series_id", &previousSeries).Error; err != nil {
+		return nil, nil, err
+	}
+	if len(previousSeries) > 0 {
+		var previousMembers []models.SnowflakeID
+		if err := tx.Model(&models.SeriesArticle{}).Where("series_id IN ?", previousSeries).Pluck("article_id", &previousMembers).Error; err != nil {
+			return nil, nil, err
+		}
+		affected = append(affected, previousMembers...)
+	}
+	if err := tx.Where("article_id IN ?", articleIDs).Delete(&models.SeriesArticle{}).Error; err != nil {
+		return nil, nil, err
+	}
+
+	members := make([]models.SeriesArticle, 0, len(articleIDs))
+	for i, articleID := range articleIDs {
+		members = append(members, models.SeriesArticle{SeriesID: seriesID, ArticleID: articleID, Position: i + 1})
+		affected = append(affected, articleID)
+	}
+	if err := tx.Create(&members).Error; err != nil {
+		return nil, nil, err
+	}
+	return affected, previousSeries, nil
+}
+
+// revalidateSeries 刷新系列页、成员被移入本系列的原系列页，以及所有涉及文章的页面
+func (sh *SeriesHandler) revalidateSeries(series models.Series, articleIDs []models.SnowflakeID, previousSeries []models.SnowflakeID) {
+	paths := []string{"/series/" + string(series.ID)}
+	if series.Slug != "" {
+		paths = append(paths, "/series/"+series.Slug)
+	}
+
+	var others []models.Series
+	if len(previousSeries) > 0 {
+		if err := sh.DB.Select("id,slug").Where("id IN ?", previousSeries).Find(&others).Error; err != nil {
+			log.Errorf("Failed to load previous series for revalidation: %v", err)
+		}
+	}
+	for _, other := range others {
+		paths = append(paths, "/series/"+string(other.ID))
+		if other.Slug != "" {
+			paths = append(paths, "/series/"+other.Slug)
+		}
+	}
+
+	var articles []models.Article
+	if len(articleIDs) > 0 {
+		if err := sh.DB.Select("id,slug").Where("id IN ?", articleIDs).Find(&articles).Error; err != nil {
+			log.Errorf("Failed to load series articles for revalidation: %v", err)
+		}
+	}
+	for _, article := range articles {
+		paths = append(paths, "/post/"+string(article.ID))
+		if article.Slug != "" {
+			paths = append(paths, "/post/"+article.Slug)
+		}
+	}
+	sh.Redis.Del(context.Background(), sitemapCacheKey)
+	sh.KafkaProducer.ProduceMessage(kafka.RevalidateUpdateTopic, "path", strings.Join(paths, ","))
+}
+
+// GetSeriesList 获取所有系列及其已发布文章数量
+func (sh *SeriesHandler) GetSeriesList(c *fiber.Ctx) error {
+	var seriesList []SeriesWithCount
+	result := sh.DB.Table("series").
+		Select("series.id, series.title, series.slug, series.description, COUNT(article.id) AS article_count").
+		Joins("LEFT JOIN series_article ON series_article.series_id = series.id").
+		Joins("LEFT JOIN article ON article.id = series_article.article_id AND article.is_deleted = false AND article.status = ?", models.ArticleStatusPublished).
+		Where("series.is_deleted", false).
+		Group("series.id, series.title, series.slug, series.description").
+		Order("series.created_at DESC").
+		Scan(&seriesList)
+	if result.Error != nil {
+		log.Errorf("Failed to get series: %v", result.Error)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	return c.JSON(seriesList)
+}
+
+// GetSeries 获取系列详情及按顺序排列的已发布文章，支持 ID 或 slug
+func (sh *SeriesHandler) GetSeries(c *fiber.Ctx) error {
+	key := c.Params("id")
+	var series models.Series
+	result := sh.DB.Where("is_deleted", false).Where("slug = ?", key).Limit(1).Find(&series)
+	if result.Error == nil && result.RowsAffected == 0 {
+		if _, err := common.ParseString(key); err == nil {
+			result = sh.DB.Where("is_deleted", false).Where("id = ?", key).Limit(1).Find(&series)
+		}
+	}
+	if result.Error != nil {
+		log.Errorf("Failed to get series: %v", result.Error)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	if result.RowsAffected == 0 {
+		return c.Status(404).JSON(fiber.Map{"error": "Series not found"})
+	}
+
+	members, err := publishedSeriesMembers(sh.DB, series.ID)
+	if err != nil {
+		log.Errorf("Failed to get series articles: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	return c.JSON(fiber.Map{
+		"id":          series.ID,
+		"title":       series.Title,
+		"slug":        series.Slug,
+		"description": series.Description,
+		"articles":    members,
+	})
+}
+
+// CreateSeries 新建系列
+func (sh *SeriesHandler) CreateSeries(c *fiber.Ctx) error {
+	var input seriesInput
+	if err := c.BodyParser(&input); err != nil {
+		log.Error(err)
+		return &common.BusinessException{
+			Code:    5000,
+			Message: "无法解析JSON",
+		}
+	}
+	if strings.TrimSpace(input.Title) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "title is required"})
+	}
+
+	id, err := common.GenerateID()
+	if err != nil {
+		log.Errorf("Failed to generate series id: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	series := models.Series{
+		BaseModel:   models.BaseModel{ID: models.SnowflakeID(id)},
+		Title:       strings.TrimSpace(input.Title),
+		Description: input.Description,
+	}
+
+	var affected, previousSeries []models.SnowflakeID
+	err = sh.DB.Transaction(func(tx *gorm.DB) error {
+		slug, err := resolveSeriesSlug(tx, series.ID, input.Slug, series.Title)
+		if err != nil {
+			return err
+		}
+		series.Slug = slug
+		if err := tx.Create(&series).Error; err != nil {
+			return err
+		}
+		affected, previousSeries, err = replaceSeriesMembers(tx, series.ID, input.ArticleIDs)
+		return err
+	})
+	if err != nil {
+		var be *common.BusinessException
+		if errors.As(err, &be) {
+			return err
+		}
+		log.Errorf("Failed to create series: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	sh.revalidateSeries(series, affected, previousSeries)
+	return c.Status(fiber.StatusCreated).JSON(series)
+}
+
+// UpdateSeries 更新系列信息及文章顺序
+func (sh *SeriesHandler) UpdateSeries(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var input seriesInput
+	if err := c.BodyParser(&input); err != nil {
+		log.Error(err)
+		return &common.BusinessException{
+			Code:    5000,
+			Message: "无法解析JSON",
+		}
+	}
+	if strings.TrimSpace(input.Title) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "title is required"})
+	}
+
+	var series models.Series
+	var affected, previousSeries []models.SnowflakeID
+	err := sh.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("is_deleted", false).Take(&series, "id = ?", id).Error; err != nil {
+			return err
+		}
+		slug := series.Slug
+		if input.Slug != "" && input.Slug != series.Slug {
+			var err error
+			if slug, err = resolveSeriesSlug(tx, series.ID, input.Slug, input.Title); err != nil {
+				return err
+			}
+		}
+		series.Title = strings.TrimSpace(input.Title)
+		series.Slug = slug
+		series.Description = input.Description
+		if err := tx.Model(&series).Updates(map[string]interface{}{
+			"title":       series.Title,
+			"slug":        series.Slug,
+			"description": series.Description,
+		}).Error; err != nil {
+			return err
+		}
+		var err error
+		affected, previousSeries, err = replaceSeriesMembers(tx, series.ID, input.ArticleIDs)
+		return err
+	})
+	if err != nil {
+		var be *common.BusinessException
+		if errors.As(err, &be) {
+			return err
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Series not found"})
+		}
+		log.Errorf("Failed to update series: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	sh.revalidateSeries(series, affected, previousSeries)
+	return c.JSON(series)
+}
+
+// DeleteSeries 删除系列，文章本身保留
+func (sh *SeriesHandler) DeleteSeries(c *fiber.Ctx) error {
+	id := c.Params("id")
+	var series models.Series
+	var affected, previousSeries []models.SnowflakeID
+	err := sh.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("is_deleted", false).Take(&series, "id = ?", id).Error; err != nil {
+			return err
+		}
+		var err error
+		if affected, previousSeries, err = replaceSeriesMembers(tx, series.ID, nil); err != nil {
+			return err
+		}
+		return tx.Model(&series).Update("is_deleted", true).Error
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": "Series not found"})
+		}
+		log.Errorf("Failed to delete series: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to delete series"})
+	}
+
+	sh.revalidateSeries(series, affected, previousSeries)
+	return c.JSON(fiber.Map{"message": "Series deleted successfully"})
+}
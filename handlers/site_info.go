@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// siteInfo 站点基础信息，来自 BlogConfigHandler 维护的 blog_config
+type siteInfo struct {
+	URL         string
+	Title       string
+	Description string
+	Author      string
+}
+
+// loadSiteInfo 读取 blog_config 中的站点信息，站点地址缺省时回退到 APP_FRONTEND_URL
+func loadSiteInfo(ctx context.Context, rdb *redis.Client) siteInfo {
+	config, _ := rdb.HGetAll(ctx, "blog_config").Result()
+	lookup := func(keys ...string) string {
+		for _, key := range keys {
+			var value string
+			if raw, ok := config[key]; ok && json.Unmarshal([]byte(raw), &value) == nil && strings.TrimSpace(value) != "" {
+				return strings.TrimSpace(value)
+			}
+		}
+		return ""
+	}
+
+	info := siteInfo{
+		URL:         lookup("siteUrl", "baseUrl", "url"),
+		Title:       lookup("siteName", "title"),
+		Description: lookup("description", "siteDescription"),
+		Author:      lookup("author", "nickname"),
+	}
+	if info.URL == "" {
+		info.URL = os.Getenv("APP_FRONTEND_URL")
+	}
+	info.URL = strings.TrimRight(info.URL, "/")
+	if info.Title == "" {
+		info.Title = info.URL
+	}
+	return info
+}
+
+// articleURL 文章的站点链接，优先使用 slug
+func (s siteInfo) articleURL(id string, slug string) string {
+	if slug != "" {
+		return s.URL + "/post/" + slug
+	}
+	return s.URL + "/post/" + id
+}
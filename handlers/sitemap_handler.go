@@ -0,0 +1,238 @@
+package handlers
+
+import (
+	"blog-server-go/models"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+)
+
+type SitemapHandler struct {
+	BaseHandler
+}
+
+// sitemapCacheKey sitemap 缓存的 Redis hash，文章、友链、系列变更时整体删除
+const sitemapCacheKey = "sitemapCache"
+
+const (
+	// sitemapURLLimit sitemap 协议规定单个文件最多 50000 个 URL
+	sitemapURLLimit  = 50000
+	sitemapCacheTTL  = 6 * time.Hour
+	sitemapXMLNS     = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	sitemapIndexPage = "index"
+)
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	XMLNS    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapURL `xml:"sitemap"`
+}
+
+// sitemapLastMod 用于聚合查询标签、系列的最后更新时间
+type sitemapLastMod struct {
+	Name      string
+	Slug      string
+	ID        models.SnowflakeID
+	UpdatedAt time.Time
+}
+
+// GetSitemap 输出 sitemap；URL 超过协议上限时 /sitemap.xml 为索引，分片通过 ?page=N 获取
+func (sh *SitemapHandler) GetSitemap(c *fiber.Ctx) error {
+	field := sitemapIndexPage
+	if pageStr := c.Query("page"); pageStr != "" {
+		page, err := strconv.Atoi(pageStr)
+		if err != nil || page < 1 {
+			return c.Status(fiber.StatusBadRequest).SendString("Invalid page")
+		}
+		field = "page:" + strconv.Itoa(page)
+	}
+
+	ctx := context.Background()
+	body, err := sh.Redis.HGet(ctx, sitemapCacheKey, field).Result()
+	if err != nil {
+		// 索引已缓存而分片不在缓存中，说明页码超出范围，无需重新生成
+		if field != sitemapIndexPage && sh.Redis.HExists(ctx, sitemapCacheKey, sitemapIndexPage).Val() {
+			return c.Status(fiber.StatusNotFound).SendString("Sitemap page not found")
+		}
+		pages, err := sh.buildSitemap(ctx)
+		if err != nil {
+			log.Errorf("Failed to build sitemap: %v", err)
+			return c.Status(fiber.StatusInternalServerError).SendString("Failed to build sitemap")
+		}
+		values := make([]interface{}, 0, len(pages)*2)
+		for key, value := range pages {
+			values = append(values, key, value)
+		}
+		sh.Redis.HSet(ctx, sitemapCacheKey, values...)
+		sh.Redis.Expire(ctx, sitemapCacheKey, sitemapCacheTTL)
+
+		var ok bool
+		if body, ok = pages[field]; !ok {
+			return c.Status(fiber.StatusNotFound).SendString("Sitemap page not found")
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "application/xml; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, "public, max-age=3600")
+	return c.SendString(body)
+}
+
+// GetRobots 输出 robots.txt，指向当前 sitemap
+func (sh *SitemapHandler) GetRobots(c *fiber.Ctx) error {
+	var builder strings.Builder
+	builder.WriteString("User-agent: *\n")
+	builder.WriteString("Allow: /\n")
+	builder.WriteString("Disallow: /admin\n\n")
+	builder.WriteString("Sitemap: " + c.BaseURL() + strings.TrimSuffix(c.Path(), "robots.txt") + "sitemap.xml\n")
+
+	c.Set(fiber.HeaderContentType, "text/plain; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, "public, max-age=86400")
+	return c.SendString(builder.String())
+}
+
+// buildSitemap 生成全部 sitemap 内容，返回 缓存字段 -> XML
+func (sh *SitemapHandler) buildSitemap(ctx context.Context) (map[string]string, error) {
+	site := loadSiteInfo(ctx, sh.Redis)
+	urls, err := sh.collectSitemapURLs(site)
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make(map[string]string)
+	if len(urls) <= sitemapURLLimit {
+		body, err := marshalXML(sitemapURLSet{XMLNS: sitemapXMLNS, URLs: urls})
+		if err != nil {
+			return nil, err
+		}
+		pages[sitemapIndexPage] = string(body)
+		return pages, nil
+	}
+
+	index := sitemapIndex{XMLNS: sitemapXMLNS}
+	for start, page := 0, 1; start < len(urls); start, page = start+sitemapURLLimit, page+1 {
+		end := start + sitemapURLLimit
+		if end > len(urls) {
+			end = len(urls)
+		}
+		body, err := marshalXML(sitemapURLSet{XMLNS: sitemapXMLNS, URLs: urls[start:end]})
+		if err != nil {
+			return nil, err
+		}
+		pages["page:"+strconv.Itoa(page)] = string(body)
+		index.Sitemaps = append(index.Sitemaps, sitemapURL{
+			Loc:     fmt.Sprintf("%s/sitemap.xml?page=%d", site.URL, page),
+			LastMod: latestLastMod(urls[start:end]),
+		})
+	}
+	body, err := marshalXML(index)
+	if err != nil {
+		return nil, err
+	}
+	pages[sitemapIndexPage] = string(body)
+	return pages, nil
+}
+
+// collectSitemapURLs 汇总首页、友链页、已发布文章、标签页和系列页
+func (sh *SitemapHandler) collectSitemapURLs(site siteInfo) ([]sitemapURL, error) {
+	var articles []models.Article
+	if err := publishedArticles(sh.DB.Select("id,slug,updated_at")).Order("updated_at DESC").Find(&articles).Error; err != nil {
+		return nil, err
+	}
+
+	var friendsUpdated sitemapLastMod
+	if err := sh.DB.Model(&models.FriendLink{}).Select("MAX(updated_at) AS updated_at").
+		Where("is_deleted = ? AND is_active = ?", false, true).Scan(&friendsUpdated).Error; err != nil {
+		return nil, err
+	}
+
+	var tags []sitemapLastMod
+	if err := sh.DB.Table("tag").
+		Select("tag.name, MAX(article.updated_at) AS updated_at").
+		Joins("JOIN article_tag ON article_tag.tag_id = tag.id").
+		Joins("JOIN article ON article.id = article_tag.article_id AND article.is_deleted = false AND article.status = ?", models.ArticleStatusPublished).
+		Where("tag.is_deleted", false).
+		Group("tag.name").
+		Order("tag.name").
+		Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	var seriesList []sitemapLastMod
+	if err := sh.DB.Table("series").
+		Select("series.id, series.slug, GREATEST(series.updated_at, MAX(article.updated_at)) AS updated_at").
+		Joins("JOIN series_article ON series_article.series_id = series.id").
+		Joins("JOIN article ON article.id = series_article.article_id AND article.is_deleted = false AND article.status = ?", models.ArticleStatusPublished).
+		Where("series.is_deleted", false).
+		Group("series.id, series.slug, series.updated_at").
+		Order("series.id").
+		Scan(&seriesList).Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]sitemapURL, 0, len(articles)+len(tags)+len(seriesList)+2)
+	var latest time.Time
+	if len(articles) > 0 {
+		latest = articles[0].UpdatedAt
+	}
+	urls = append(urls, sitemapURL{Loc: site.URL + "/", LastMod: formatLastMod(latest)})
+	urls = append(urls, sitemapURL{Loc: site.URL + "/friends", LastMod: formatLastMod(friendsUpdated.UpdatedAt)})
+	for _, article := range articles {
+		urls = append(urls, sitemapURL{
+			Loc:     site.articleURL(string(article.ID), article.Slug),
+			LastMod: formatLastMod(article.UpdatedAt),
+		})
+	}
+	for _, tag := range tags {
+		urls = append(urls, sitemapURL{
+			Loc:     site.URL + "/tags/" + url.PathEscape(tag.Name),
+			LastMod: formatLastMod(tag.UpdatedAt),
+		})
+	}
+	for _, series := range seriesList {
+		key := series.Slug
+		if key == "" {
+			key = string(series.ID)
+		}
+		urls = append(urls, sitemapURL{
+			Loc:     site.URL + "/series/" + key,
+			LastMod: formatLastMod(series.UpdatedAt),
+		})
+	}
+	return urls, nil
+}
+
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// latestLastMod 分片中最新的 lastmod，RFC3339 UTC 字符串可直接比较
+func latestLastMod(urls []sitemapURL) string {
+	latest := ""
+	for _, u := range urls {
+		if u.LastMod > latest {
+			latest = u.LastMod
+		}
+	}
+	return latest
+}
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"blog-server-go/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+type TagHandler struct {
+	BaseHandler
+}
+
+// TagWithCount 带已发布文章数量的标签
+type TagWithCount struct {
+	ID           models.SnowflakeID `json:"id"`
+	Name         string             `json:"name"`
+	ArticleCount int64              `json:"articleCount"`
+}
+
+// queryTagCounts 所有标签及其已发布文章数量，按文章数倒序
+func queryTagCounts(db *gorm.DB) ([]TagWithCount, error) {
+	var tags []TagWithCount
+	err := db.Table("tag").
+		Select("tag.id, tag.name, COUNT(article.id) AS article_count").
+		Joins("LEFT JOIN article_tag ON article_tag.tag_id = tag.id").
+		Joins("LEFT JOIN article ON article.id = article_tag.article_id AND article.is_deleted = false AND article.status = ?", models.ArticleStatusPublished).
+		Where("tag.is_deleted", false).
+		Group("tag.id, tag.name").
+		Order("article_count DESC, tag.name").
+		Scan(&tags).Error
+	return tags, err
+}
+
+// GetTags 获取所有标签及其已发布文章数量
+func (th *TagHandler) GetTags(c *fiber.Ctx) error {
+	tags, err := queryTagCounts(th.DB)
+	if err != nil {
+		log.Errorf("Failed to get tags: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+	if c.Query("hideEmpty") == "true" {
+		filtered := make([]TagWithCount, 0, len(tags))
+		for _, tag := range tags {
+			if tag.ArticleCount > 0 {
+				filtered = append(filtered, tag)
+			}
+		}
+		tags = filtered
+	}
+	return c.JSON(tags)
+}
+
+// GetArticlesByTag 获取某个标签下的已发布文章
+func (th *TagHandler) GetArticlesByTag(c *fiber.Ctx) error {
+	name := c.Params("name")
+	var articles []models.Article
+	result := publishedArticles(th.DB.Select("id,title,slug,tag,created_at,updated_at,sort_order,status,publish_at")).
+		Where("id IN (?)", taggedArticleIDs(th.DB, name)).
+		Order("sort_order desc").Order("created_at desc").
+		Find(&articles)
+	if result.Error != nil {
+		log.Errorf("Failed to get articles by tag: %v", result.Error)
+		return c.Status(500).JSON(fiber.Map{"error": "Internal Server Error"})
+	}
+
+	attachSummaries(th.DB, articles)
+	for i := range articles {
+		articles[i].Tags = parseTagNames(articles[i].Tag)
+	}
+
+	return c.JSON(fiber.Map{
+		"tag":      name,
+		"total":    len(articles),
+		"articles": articles,
+	})
+}
@@ -2,22 +2,32 @@ package kafka
 
 import (
 	"blog-server-go/models"
-	"bytes"
+	"blog-server-go/services"
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/gofiber/fiber/v2/log"
-	"github.com/pgvector/pgvector-go"
+	"github.com/meilisearch/meilisearch-go"
 	"github.com/redis/go-redis/v9"
 	"github.com/segmentio/kafka-go"
 	"gorm.io/gorm"
-	"io"
 	"net/http"
 	"os"
 	"time"
 )
 
-func ArticleHandler(msg kafka.Message, db *gorm.DB, redis *redis.Client) {
+// NewArticleHandler 先将变更的文章增量同步到搜索索引，再执行 ArticleHandler 的其余处理
+// 索引同步放在最前，避免后续的页面刷新或向量生成失败提前返回导致索引滞后
+// 摘要和向量使用与 HTTP 接口相同的模型服务
+func NewArticleHandler(meili meilisearch.ServiceManager, ai *services.AIServices) MessageHandlerFunc {
+	return func(msg kafka.Message, db *gorm.DB, redis *redis.Client) {
+		if err := services.SyncArticleDocument(meili, db, redis, string(msg.Value)); err != nil {
+			log.Errorf("Failed to sync article %s to Meilisearch: %v", msg.Value, err)
+		}
+		ArticleHandler(msg, db, redis, ai)
+	}
+}
+
+func ArticleHandler(msg kafka.Message, db *gorm.DB, redis *redis.Client, ai *services.AIServices) {
 	fmt.Printf("Processing article update: %s = %s\n", string(msg.Key), string(msg.Value))
 	httpClient := &http.Client{Timeout: 10 * time.Second} // defining the http client here
 	secret := os.Getenv("NEXT_SECRET")
@@ -39,6 +49,8 @@ func ArticleHandler(msg kafka.Message, db *gorm.DB, redis *redis.Client) {
 
 	var ctx = context.Background()
 	id := string(msg.Value)
+	// 文章变更后订阅源和 sitemap 需要重新生成
+	redis.Del(ctx, "feedCache", "sitemapCache")
 	var article models.Article
 	result := db.Take(&article, id)
 	if result.Error != nil {
@@ -46,156 +58,35 @@ func ArticleHandler(msg kafka.Message, db *gorm.DB, redis *redis.Client) {
 		return
 	}
 
-	// 准备请求体
-	reqBody := map[string]interface{}{
-		"model": "google/gemini-2.5-flash",
-		"messages": []map[string]interface{}{
-			{
-				"role":    "user",
-				"content": fmt.Sprintf("请为以下文章生成一段简洁的摘要，以平均阅读速度300字/分钟为基准，根据摘要字数计算预计阅读时间,将摘要与阅读时间合并为一段，格式为\"[摘要内容]（预计阅读时间：X分钟）\",控制在100字以内：\n\n%s", article.Content),
-			},
-		},
-		"max_tokens": 150,
-	}
-
-	jsonBody, err := json.Marshal(reqBody)
-
-	if err != nil {
-		log.Error("Error marshaling request body:", err)
-	}
-
-	// 创建请求
-	req, err := http.NewRequest("POST", "https://llm.ooxo.cc/v1/chat/completions", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		log.Error("Error creating request:", err)
-	}
-
-	// 设置请求头
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+os.Getenv("OPENROUTER_API_KEY"))
-	req.Header.Set("HTTP-Referer", "https://zzfzzf.com")
-	req.Header.Set("X-Title", "Blog Article Summarizer")
-
-	// 设置超时
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	req = req.WithContext(ctx)
-
-	// 发送请求
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Error("Error calling LLM API:", err)
-	}
-	defer resp.Body.Close()
-
-	// 读取响应内容
-	respBody, err := io.ReadAll(resp.Body)
-
-	if err != nil {
-		log.Error("Error reading response:", err)
-	}
-	log.Error("LLM API Response:", string(respBody))
-
-	// 检查响应状态
-	if resp.StatusCode != http.StatusOK {
-		log.Error("LLM API error")
-		return
-	}
-
-	// 解析响应
-	var llmResult map[string]interface{}
-	if err := json.NewDecoder(bytes.NewReader(respBody)).Decode(&llmResult); err != nil {
-		log.Error("Failed to parse response")
-		return
-	}
-
-	// 提取摘要并保存到 Redis
-	if choices, ok := llmResult["choices"].([]interface{}); ok && len(choices) > 0 {
-		if choice, ok := choices[0].(map[string]interface{}); ok {
-			if message, ok := choice["message"].(map[string]interface{}); ok {
-				if content, ok := message["content"].(string); ok {
-					log.Info("LLM API Response:", content)
-					err = redis.HSet(ctx, "articleSummary", id, content).Err()
-					if err != nil {
-						log.Error("Failed to save summary to Redis:", err)
-					}
-				}
-			}
+	// 同时刷新 slug 形式的文章路径
+	if article.Slug != "" {
+		data = []byte(fmt.Sprintf(`{"path": ["/post/%s"],"secret": "%s"}`, article.Slug, secret))
+		if body, err = SendRequest(httpClient, "/api/revalidatePath", data); err != nil {
+			log.Error("Failed to revalidate slug path:", err)
+		} else {
+			log.Info("Response Body from slug revalidation:", string(body))
 		}
 	}
 
-	// 生成向量并保存
-	text := article.Title
-	if article.Content != "" {
-		text = fmt.Sprintf("标题：%s\n内容：%s", article.Title, article.Content)
-	} else if article.Summary != "" {
-		text = fmt.Sprintf("标题：%s\n摘要：%s", article.Title, article.Summary)
+	// 按章节重新切分并更新片段向量，供 RAG 检索
+	if _, err := services.SyncArticleChunks(ctx, db, ai.Embedding, article); err != nil {
+		log.Error("Failed to sync article chunks:", err)
 	}
 
-	embedding, err := generateEmbedding(text)
-	if err != nil {
-		log.Error("Failed to generate embedding:", err)
-		return
+	// 正文变化时重新生成摘要，未变化时跳过
+	if summary, generated, err := services.GenerateArticleSummary(ctx, db, ai.Summary, article, false); err != nil {
+		log.Error("Failed to generate summary:", err)
+	} else if generated {
+		log.Info("Article summary generated:", summary.ShortSummary)
 	}
 
-	// 保存向量到数据库
-	vectorStr := pgvector.NewVector(embedding).String()
-	if err := db.Exec("UPDATE article SET embedding = ? WHERE id = ?", vectorStr, article.ID).Error; err != nil {
-		log.Error("Failed to save embedding:", err)
+	// 生成向量并保存，记录生成向量的模型和维度
+	if err := services.EmbedArticle(ctx, db, ai.Embedding, article); err != nil {
+		log.Error("Failed to generate embedding:", err)
 		return
 	}
+	// 向量变化后相关文章需要重新计算
+	redis.Del(context.Background(), "articleRelated")
 
 	log.Info("Article vectorized successfully:", id)
 }
-
-// generateEmbedding 生成文章向量
-func generateEmbedding(text string) ([]float32, error) {
-	reqBody := map[string]interface{}{
-		"model": "text-embedding-v3",
-		"input": []string{text},
-	}
-
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", "http://embed.ooxo.cc/v1/embeddings", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	req = req.WithContext(ctx)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		respBody, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s", string(respBody))
-	}
-
-	var result struct {
-		Data []struct {
-			Embedding []float32 `json:"embedding"`
-		} `json:"data"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if len(result.Data) == 0 {
-		return nil, fmt.Errorf("no embedding returned")
-	}
-
-	return result.Data[0].Embedding, nil
-}
@@ -1,6 +1,7 @@
 package kafka
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -14,6 +15,8 @@ import (
 
 func FriendHandler(msg kafka.Message, db *gorm.DB, redis *redis.Client) {
 	fmt.Printf("Processing friend update: %s = %s\n", string(msg.Key), string(msg.Value))
+	// 友链页面的 lastmod 随之变化，sitemap 需要重新生成
+	redis.Del(context.Background(), "sitemapCache")
 	httpClient := &http.Client{Timeout: 10 * time.Second} // defining the http client here
 	secret := os.Getenv("NEXT_SECRET")
 	data := []byte(fmt.Sprintf(`{"path": ["/friends"],"secret": "%s"}`, secret))
@@ -104,14 +104,11 @@ func NewBaseHandler(db *gorm.DB, redisClient *redis.Client, meiliClient meilisea
 	return handlers.BaseHandler{DB: db, Redis: redisClient, Meili: meiliClient, KafkaProducer: kafkaProducer, WSHandler: wsHandler}
 }
 
-func RegisterRoutes(app *fiber.App, baseHandler handlers.BaseHandler) {
-	// 初始化 LLM 服务
-	llmService := services.NewLLMService()
-
+func RegisterRoutes(app *fiber.App, baseHandler handlers.BaseHandler, aiServices *services.AIServices, embeddingJob *services.EmbeddingJob) {
 	// 初始化 Discourse API 客户端
 	discourseClient := common.NewDiscourseAPIClient()
 
-	articleHandler := handlers.ArticleHandler{BaseHandler: baseHandler, LLMService: llmService}
+	articleHandler := handlers.ArticleHandler{BaseHandler: baseHandler, AI: aiServices, EmbeddingJob: embeddingJob}
 	discourseWebhookHandler := handlers.DiscourseWebhookHandler{BaseHandler: baseHandler}
 	commentsHandler := handlers.CommentsHandler{BaseHandler: baseHandler, DiscourseClient: discourseClient}
 	appUserHandler := handlers.AppUserHandler{BaseHandler: baseHandler}
@@ -121,6 +118,11 @@ func RegisterRoutes(app *fiber.App, baseHandler handlers.BaseHandler) {
 	taskHandler := handlers.TaskHandler{BaseHandler: baseHandler}
 	financialTransactionHandler := handlers.FinancialTransactionHandler{BaseHandler: baseHandler}
 	statsHandler := handlers.StatsHandler{BaseHandler: baseHandler}
+	tagHandler := handlers.TagHandler{BaseHandler: baseHandler}
+	seriesHandler := handlers.SeriesHandler{BaseHandler: baseHandler}
+	feedHandler := handlers.FeedHandler{BaseHandler: baseHandler}
+	sitemapHandler := handlers.SitemapHandler{BaseHandler: baseHandler}
+	searchHandler := handlers.SearchHandler{BaseHandler: baseHandler}
 	allHandlers := &routes.Handlers{
 		ArticleHandler:              articleHandler,
 		DiscourseWebhookHandler:     discourseWebhookHandler,
@@ -132,6 +134,11 @@ func RegisterRoutes(app *fiber.App, baseHandler handlers.BaseHandler) {
 		TaskHandler:                 taskHandler,
 		FinancialTransactionHandler: financialTransactionHandler,
 		StatsHandler:                statsHandler,
+		TagHandler:                  tagHandler,
+		SeriesHandler:               seriesHandler,
+		FeedHandler:                 feedHandler,
+		SitemapHandler:              sitemapHandler,
+		SearchHandler:               searchHandler,
 	}
 	routes.SetupRoutes(app, allHandlers)
 }
@@ -170,21 +177,38 @@ func main() {
 	// 初始化WebSocketHandler
 	wsHandler := NewWebSocketHandler(db, redisClient, meiliClient)
 
+	// 初始化模型服务，HTTP 接口与 Kafka 消费者共用
+	aiServices, err := services.NewAIServices(config.LoadAIConfig())
+	if err != nil {
+		log.Fatalf("Error initializing AI services: %v", err)
+	}
+
 	// 初始化BaseHandler
 	kafkaProducer := kafka.NewProducer()
 	baseHandler := NewBaseHandler(db, redisClient, meiliClient, kafkaProducer, wsHandler)
 	topicHandlers := map[string]kafka.MessageHandlerFunc{
-		kafka.ArticleUpdateTopic:    kafka.ArticleHandler,
+		kafka.ArticleUpdateTopic:    kafka.NewArticleHandler(meiliClient, aiServices),
 		kafka.FriendUpdateTopic:     kafka.FriendHandler,
 		kafka.RevalidateUpdateTopic: kafka.RevalidateHandler,
 	}
 	// 初始化Kafka消费者
 	kafkaConsumer := kafka.CreateMultiConsumer(topicHandlers, db, redisClient)
 
+	// 搜索索引的字段设置变化时重建索引
+	go func() {
+		if err := services.MigrateArticleIndex(meiliClient, db, redisClient); err != nil {
+			log.Errorf("Failed to migrate search index: %v", err)
+		}
+	}()
+
+	// 继续因重启而中断的向量重建任务
+	embeddingJob := services.NewEmbeddingJob(db, redisClient, aiServices.Embedding)
+	go embeddingJob.ResumeInterrupted()
+
 	// 注册路由
-	RegisterRoutes(app, baseHandler)
+	RegisterRoutes(app, baseHandler, aiServices, embeddingJob)
 	// 开始定时任务
-	go tasks.StartCronJobs()
+	go tasks.StartCronJobs(db, kafkaProducer)
 	// 启动服务
 	StartServices(app, kafkaConsumer)
 
@@ -19,6 +19,14 @@ func ResponseMiddleware(c *fiber.Ctx) error {
 	if strings.Contains(c.Path(), "/export/") {
 		return c.Next()
 	}
+	// 检查是否是 RSS / Atom / JSON Feed 订阅源
+	if strings.Contains(c.Path(), "/feeds/") {
+		return c.Next()
+	}
+	// 检查是否是 sitemap / robots
+	if strings.HasSuffix(c.Path(), "/sitemap.xml") || strings.HasSuffix(c.Path(), "/robots.txt") {
+		return c.Next()
+	}
 	// 检查是否是 SSE 流式响应
 	if strings.Contains(c.Path(), "/rag/question") {
 		return c.Next()
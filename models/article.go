@@ -1,16 +1,41 @@
 package models
 
-import "github.com/pgvector/pgvector-go"
+import (
+	"time"
+
+	"github.com/pgvector/pgvector-go"
+)
+
+// 文章状态
+const (
+	ArticleStatusDraft     = "draft"
+	ArticleStatusScheduled = "scheduled"
+	ArticleStatusPublished = "published"
+	ArticleStatusArchived  = "archived"
+)
 
 type Article struct {
 	BaseModel
 	Title            string          `json:"title"`
+	Slug             string          `json:"slug"`
 	Content          string          `json:"content"`
 	ViewCount        int             `json:"viewCount"`
 	Tag              string          `json:"tag"`
+	Tags             []string        `json:"tags" gorm:"-"`
 	SortOrder        int             `json:"sortOrder"`
 	IsActive         bool            `json:"isActive"`
+	Status           string          `json:"status" gorm:"index"`
+	PublishAt        *time.Time      `json:"publishAt"`
+	TrashedAt        *time.Time      `json:"trashedAt,omitempty"` // 移入回收站的时间
 	DiscourseTopicID int64           `json:"discourseTopicId" gorm:"index"`
+	MetaDescription  string          `json:"metaDescription"` // 搜索引擎结果中的描述，为空时使用摘要
 	Summary          string          `json:"summary" gorm:"-"`
-	Embedding         pgvector.Vector `json:"-" gorm:"type:vector(1024)"`
+	ReadingMinutes   int             `json:"readingMinutes,omitempty" gorm:"-"` // 根据正文计算，随摘要一起保存
+	Series           *SeriesNavigation `json:"series,omitempty" gorm:"-"`
+	Lang             string          `json:"lang,omitempty" gorm:"-"`             // 返回译文时的语言
+	TranslationStale bool            `json:"translationStale,omitempty" gorm:"-"` // 译文是否落后于原文
+	Embedding         pgvector.Vector `json:"-" gorm:"type:vector"`
+	// 生成 Embedding 的模型和维度，只有与当前模型一致的向量才参与检索
+	EmbeddingModel      string `json:"-"`
+	EmbeddingDimensions int    `json:"-"`
 }
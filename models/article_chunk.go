@@ -0,0 +1,18 @@
+package models
+
+import "github.com/pgvector/pgvector-go"
+
+// ArticleChunk 文章按 Markdown 标题和 token 预算切分后的片段，每个片段一个向量
+type ArticleChunk struct {
+	BaseModel
+	ArticleID   SnowflakeID     `json:"articleId" gorm:"index"`
+	Position    int             `json:"position"`
+	HeadingPath string          `json:"headingPath"` // 例如 "安装 > Linux"
+	Content     string          `json:"content"`
+	ContentHash string          `json:"contentHash"` // 文章标题、标题路径与内容的 sha256，未变化的片段不重新生成向量
+	TokenCount  int             `json:"tokenCount"`
+	Embedding   pgvector.Vector `json:"-" gorm:"type:vector"`
+	// 生成向量的模型和维度，模型切换后旧版本的片段会重新生成向量
+	EmbeddingModel      string `json:"embeddingModel"`
+	EmbeddingDimensions int    `json:"embeddingDimensions"`
+}
@@ -0,0 +1,23 @@
+package models
+
+// 修订来源
+const (
+	RevisionSourceBaseline  = "baseline"  // 首次记录修订前的原始内容
+	RevisionSourceAdmin     = "admin"     // 后台接口
+	RevisionSourceDiscourse = "discourse" // Discourse webhook
+	RevisionSourceRestore   = "restore"   // 从历史修订恢复
+	RevisionSourceImport    = "import"    // Markdown zip 导入
+)
+
+// ArticleRevision 文章修订记录，只追加不修改
+type ArticleRevision struct {
+	BaseModel
+	ArticleID    SnowflakeID `json:"articleId" gorm:"index"`
+	Version      int         `json:"version"`
+	Title        string      `json:"title"`
+	Content      string      `json:"content,omitempty"`
+	Tag          string      `json:"tag"`
+	Source       string      `json:"source"`
+	Editor       string      `json:"editor"`
+	RestoredFrom int         `json:"restoredFrom,omitempty"` // 恢复来源的版本号
+}
@@ -0,0 +1,8 @@
+package models
+
+// ArticleSlugRedirect 文章旧 slug 到文章的映射，用于 301 跳转
+type ArticleSlugRedirect struct {
+	BaseModel
+	OldSlug   string      `json:"oldSlug" gorm:"uniqueIndex"`
+	ArticleID SnowflakeID `json:"articleId" gorm:"index"`
+}
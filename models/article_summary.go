@@ -0,0 +1,18 @@
+package models
+
+// SummaryModelManual 管理员手动编辑的摘要记录的模型名
+const SummaryModelManual = "manual"
+
+// ArticleSummary 文章的 AI 摘要，每篇文章一条
+// 正文哈希与提示词版本都未变化时不重新生成
+type ArticleSummary struct {
+	BaseModel
+	ArticleID      SnowflakeID `json:"articleId" gorm:"uniqueIndex"`
+	ShortSummary   string      `json:"shortSummary"` // 一句话摘要，用于列表、订阅源等
+	LongSummary    string      `json:"longSummary"`  // 段落摘要，用于文章详情
+	ReadingMinutes int         `json:"readingMinutes"`
+	WordCount      int         `json:"wordCount"`
+	Model          string      `json:"model"`
+	PromptVersion  int         `json:"promptVersion"`
+	ContentHash    string      `json:"contentHash"` // 生成摘要时正文的 sha256
+}
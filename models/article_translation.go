@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// ArticleTranslation 文章的其他语言版本，每篇文章每种语言一条
+type ArticleTranslation struct {
+	BaseModel
+	ArticleID       SnowflakeID `json:"articleId" gorm:"uniqueIndex:article_translation_article_locale_uidx"`
+	Locale          string      `json:"locale" gorm:"uniqueIndex:article_translation_article_locale_uidx"`
+	Title           string      `json:"title"`
+	Content         string      `json:"content,omitempty"`
+	Model           string      `json:"model"`           // 机器翻译使用的模型，管理员编辑后保留
+	Edited          bool        `json:"edited"`          // 管理员是否编辑过
+	SourceUpdatedAt time.Time   `json:"sourceUpdatedAt"` // 翻译对应的原文版本
+	Stale           bool        `json:"stale" gorm:"-"`  // 原文在翻译之后又被修改过
+}
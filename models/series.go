@@ -0,0 +1,34 @@
+package models
+
+// Series 文章系列，例如多篇连载教程
+type Series struct {
+	BaseModel
+	Title       string `json:"title"`
+	Slug        string `json:"slug" gorm:"uniqueIndex"`
+	Description string `json:"description"`
+}
+
+// SeriesArticle 系列中的文章及其顺序，一篇文章只属于一个系列
+type SeriesArticle struct {
+	SeriesID  SnowflakeID `json:"seriesId" gorm:"primaryKey"`
+	ArticleID SnowflakeID `json:"articleId" gorm:"primaryKey;uniqueIndex"`
+	Position  int         `json:"position"`
+}
+
+// SeriesArticleRef 系列导航中引用的文章
+type SeriesArticleRef struct {
+	ID    SnowflakeID `json:"id"`
+	Title string      `json:"title"`
+	Slug  string      `json:"slug"`
+}
+
+// SeriesNavigation 文章所在系列及上一篇/下一篇
+type SeriesNavigation struct {
+	ID       SnowflakeID       `json:"id"`
+	Title    string            `json:"title"`
+	Slug     string            `json:"slug"`
+	Position int               `json:"position"`
+	Total    int               `json:"total"`
+	Prev     *SeriesArticleRef `json:"prev"`
+	Next     *SeriesArticleRef `json:"next"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// Tag 标签
+type Tag struct {
+	BaseModel
+	Name string `json:"name" gorm:"uniqueIndex"`
+}
+
+// ArticleTag 文章与标签的关联
+type ArticleTag struct {
+	ArticleID SnowflakeID `json:"articleId" gorm:"primaryKey"`
+	TagID     SnowflakeID `json:"tagId" gorm:"primaryKey;index"`
+}
@@ -19,6 +19,11 @@ type Handlers struct {
 	TaskHandler                 handlers.TaskHandler
 	FinancialTransactionHandler handlers.FinancialTransactionHandler
 	StatsHandler                handlers.StatsHandler
+	TagHandler                  handlers.TagHandler
+	SeriesHandler               handlers.SeriesHandler
+	FeedHandler                 handlers.FeedHandler
+	SitemapHandler              handlers.SitemapHandler
+	SearchHandler               handlers.SearchHandler
 }
 
 func SetupRoutes(app *fiber.App, h *Handlers) {
@@ -30,24 +35,87 @@ func SetupRoutes(app *fiber.App, h *Handlers) {
 	// Articles
 	articles := v1.Group("/articles")
 	articles.Get("/", h.ArticleHandler.GetArticles)
-	articles.Post("/", middleware.AdminMiddleware(), h.ArticleHandler.CreateArticle)   // 新建文章
-	articles.Put("/:id", middleware.AdminMiddleware(), h.ArticleHandler.UpdateArticle) // 更新文章
+	articles.Post("/", middleware.AdminMiddleware(), h.ArticleHandler.CreateArticle)      // 新建文章
+	articles.Put("/:id", middleware.AdminMiddleware(), h.ArticleHandler.UpdateArticle)    // 更新文章
+	articles.Delete("/:id", middleware.AdminMiddleware(), h.ArticleHandler.DeleteArticle) // 移入回收站
+	articles.Post("/:id/restore", middleware.AdminMiddleware(), h.ArticleHandler.RestoreArticle)
+	articles.Delete("/:id/purge", middleware.AdminMiddleware(), h.ArticleHandler.PurgeArticle) // 永久删除
 	articles.Get("/search", h.ArticleHandler.SearchArticles)
-	articles.Get("/search/sync", h.ArticleHandler.SyncSQLToMeili)
+	articles.Get("/search/sync", middleware.AdminMiddleware(), h.ArticleHandler.SyncSQLToMeili) // 全量重建索引
+	articles.Get("/search/consistency", middleware.AdminMiddleware(), h.ArticleHandler.GetSearchIndexConsistency)
+	articles.Get("/slug/:slug", h.ArticleHandler.GetArticleBySlug)
+	articles.Get("/trash", middleware.AdminMiddleware(), h.ArticleHandler.GetTrashedArticles)
+	articles.Post("/slug/generate", middleware.AdminMiddleware(), h.ArticleHandler.GenerateMissingSlugs)
 	articles.Get("/:id", h.ArticleHandler.GetArticleByID)
 	articles.Put("/:id/views", h.ArticleHandler.UpdateArticleViews)
+	articles.Get("/:id/related", h.ArticleHandler.GetRelatedArticles)
+	// 修订历史
+	articles.Get("/:id/revisions", middleware.AdminMiddleware(), h.ArticleHandler.GetArticleRevisions)
+	articles.Get("/:id/revisions/diff", middleware.AdminMiddleware(), h.ArticleHandler.DiffArticleRevisions)
+	articles.Get("/:id/revisions/:version", middleware.AdminMiddleware(), h.ArticleHandler.GetArticleRevision)
+	articles.Post("/:id/revisions/:version/restore", middleware.AdminMiddleware(), h.ArticleHandler.RestoreArticleRevision)
+	// 翻译
+	articles.Get("/:id/translations", middleware.AdminMiddleware(), h.ArticleHandler.GetArticleTranslations)
+	articles.Get("/:id/translations/:locale", middleware.AdminMiddleware(), h.ArticleHandler.GetArticleTranslation)
+	articles.Post("/:id/translations/:locale", middleware.AdminMiddleware(), h.ArticleHandler.TranslateArticle) // 机器翻译，覆盖已有译文
+	articles.Put("/:id/translations/:locale", middleware.AdminMiddleware(), h.ArticleHandler.UpdateArticleTranslation)
+	articles.Delete("/:id/translations/:locale", middleware.AdminMiddleware(), h.ArticleHandler.DeleteArticleTranslation)
+	// 元数据建议
+	articles.Post("/metadata/suggest", middleware.AdminMiddleware(), h.ArticleHandler.SuggestArticleMetadata) // 根据草稿建议标签、SEO 描述、标题和 slug
 	// 摘要
+	articles.Post("/summary/regenerate", middleware.AdminMiddleware(), h.ArticleHandler.RegenerateArticleSummaries) // 后台重新生成正文变化的文章摘要
 	articles.Get("/summary/:id", h.ArticleHandler.GetArticleSummary)
-	articles.Post("/summary/:id", h.ArticleHandler.UpdateArticleSummary)
+	articles.Post("/summary/:id", middleware.AdminMiddleware(), h.ArticleHandler.UpdateArticleSummary)
 	articles.Get("/export/markdown/:id", h.ArticleHandler.ExportArticleMarkdown)
 	articles.Get("/export/all/markdown", h.ArticleHandler.ExportAllArticlesMarkdown)
+	articles.Post("/import/markdown", middleware.AdminMiddleware(), h.ArticleHandler.ImportArticlesMarkdown)
 	articles.Get("/sync2dify/:id", h.ArticleHandler.SyncToDify)
 	articles.Get("/sync/all2Dify", h.ArticleHandler.SyncAllToDify)
 	// 向量化
-	articles.Post("/vectorize/all", middleware.AdminMiddleware(), h.ArticleHandler.VectorizeAllArticles)
+	articles.Post("/vectorize/all", middleware.AdminMiddleware(), h.ArticleHandler.StartEmbeddingJob) // 后台重新生成所有向量，可继续
+	articles.Get("/vectorize/job", middleware.AdminMiddleware(), h.ArticleHandler.GetEmbeddingJob)
+	articles.Delete("/vectorize/job", middleware.AdminMiddleware(), h.ArticleHandler.PauseEmbeddingJob)
 	articles.Post("/vectorize/:id", middleware.AdminMiddleware(), h.ArticleHandler.VectorizeArticle)
 	// RAG 问答
 	articles.Post("/rag/question", h.ArticleHandler.RAGQuestion)
+	articles.Get("/rag/conversations", h.ArticleHandler.GetRAGConversations)
+	articles.Get("/rag/conversations/:id", h.ArticleHandler.GetRAGConversation)
+	articles.Delete("/rag/conversations/:id", h.ArticleHandler.DeleteRAGConversation)
+
+	// Tags
+	tags := v1.Group("/tags")
+	tags.Get("/", h.TagHandler.GetTags)
+	tags.Get("/:name/articles", h.TagHandler.GetArticlesByTag)
+
+	// Search
+	search := v1.Group("/search")
+	search.Get("/trending", h.SearchHandler.GetTrendingKeywords)
+	search.Get("/suggest", h.SearchHandler.GetSuggestions)
+	search.Get("/zero-results", middleware.AdminMiddleware(), h.SearchHandler.GetZeroResultKeywords)
+	search.Delete("/zero-results", middleware.AdminMiddleware(), h.SearchHandler.DeleteZeroResultKeyword)
+	search.Get("/settings", middleware.AdminMiddleware(), h.SearchHandler.GetSearchSettings)
+	search.Put("/settings", middleware.AdminMiddleware(), h.SearchHandler.UpdateSearchSettings) // 同义词、停用词、排序规则
+
+	// Series
+	series := v1.Group("/series")
+	series.Get("/", h.SeriesHandler.GetSeriesList)
+	series.Get("/:id", h.SeriesHandler.GetSeries)
+	series.Post("/", middleware.AdminMiddleware(), h.SeriesHandler.CreateSeries)
+	series.Put("/:id", middleware.AdminMiddleware(), h.SeriesHandler.UpdateSeries)
+	series.Delete("/:id", middleware.AdminMiddleware(), h.SeriesHandler.DeleteSeries)
+
+	// Feeds
+	feeds := v1.Group("/feeds")
+	feeds.Get("/rss.xml", h.FeedHandler.GetRSSFeed)
+	feeds.Get("/atom.xml", h.FeedHandler.GetAtomFeed)
+	feeds.Get("/feed.json", h.FeedHandler.GetJSONFeed)
+	feeds.Get("/tags/:tag/rss.xml", h.FeedHandler.GetRSSFeed)
+	feeds.Get("/tags/:tag/atom.xml", h.FeedHandler.GetAtomFeed)
+	feeds.Get("/tags/:tag/feed.json", h.FeedHandler.GetJSONFeed)
+
+	// Sitemap
+	v1.Get("/sitemap.xml", h.SitemapHandler.GetSitemap)
+	v1.Get("/robots.txt", h.SitemapHandler.GetRobots)
 
 	// Comments
 	comments := v1.Group("/comments")
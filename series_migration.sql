@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS series (
+  id bigint PRIMARY KEY,
+  created_at timestamptz,
+  updated_at timestamptz,
+  is_deleted boolean NOT NULL DEFAULT false,
+  created_by bigint,
+  updated_by bigint,
+  title text NOT NULL,
+  slug text,
+  description text
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS series_slug_uidx
+  ON series (slug)
+  WHERE slug IS NOT NULL AND slug <> '';
+
+CREATE TABLE IF NOT EXISTS series_article (
+  series_id bigint NOT NULL,
+  article_id bigint NOT NULL,
+  position integer NOT NULL,
+  PRIMARY KEY (series_id, article_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS series_article_article_id_uidx
+  ON series_article (article_id);
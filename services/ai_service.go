@@ -0,0 +1,115 @@
+package services
+
+import (
+	"blog-server-go/config"
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ChatService 对话模型
+type ChatService interface {
+	// GenerateText 生成文本（非流式）
+	GenerateText(ctx context.Context, messages []*schema.Message, opts ...model.Option) (string, error)
+	// GenerateStream 生成流式响应，每收到一段内容调用一次 callback
+	GenerateStream(ctx context.Context, messages []*schema.Message, callback func(chunk string) error, opts ...model.Option) error
+	// GenerateWithTools 使用工具调用生成（非流式），由模型决定是否调用工具
+	GenerateWithTools(ctx context.Context, messages []*schema.Message, tools []*schema.ToolInfo) (*schema.Message, error)
+	// GenerateWithToolChoice 使用工具调用生成，并按 choice 约束模型是否必须调用工具
+	GenerateWithToolChoice(ctx context.Context, messages []*schema.Message, tools []*schema.ToolInfo, choice schema.ToolChoice) (*schema.Message, error)
+	// Model 当前使用的对话模型，记录在生成结果中
+	Model() string
+}
+
+// EmbeddingService 向量模型
+type EmbeddingService interface {
+	// Embed 批量生成向量，返回顺序与 texts 一致
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+	// Model 当前使用的向量模型
+	Model() string
+	// Dimensions 向量维度，与模型一起标识向量的版本
+	Dimensions() int
+}
+
+// AIServices 按任务划分的模型服务，HTTP 处理器和 Kafka 消费者共用同一组实例
+type AIServices struct {
+	Summary        ChatService
+	QueryExpansion ChatService
+	Answer         ChatService
+	Translation    ChatService
+	Embedding      EmbeddingService
+}
+
+// NewAIServices 按配置创建各任务的模型服务
+func NewAIServices(cfg config.AIConfig) (*AIServices, error) {
+	summary, err := newChatService(cfg.Summary)
+	if err != nil {
+		return nil, fmt.Errorf("summary model: %w", err)
+	}
+	queryExpansion, err := newChatService(cfg.QueryExpansion)
+	if err != nil {
+		return nil, fmt.Errorf("query expansion model: %w", err)
+	}
+	answer, err := newChatService(cfg.Answer)
+	if err != nil {
+		return nil, fmt.Errorf("answer model: %w", err)
+	}
+	translation, err := newChatService(cfg.Translation)
+	if err != nil {
+		return nil, fmt.Errorf("translation model: %w", err)
+	}
+	embedding, err := newEmbeddingService(cfg.Embedding, cfg.EmbeddingDimensions)
+	if err != nil {
+		return nil, fmt.Errorf("embedding model: %w", err)
+	}
+	return &AIServices{
+		Summary:        summary,
+		QueryExpansion: queryExpansion,
+		Answer:         answer,
+		Translation:    translation,
+		Embedding:      embedding,
+	}, nil
+}
+
+func newChatService(cfg config.ModelConfig) (ChatService, error) {
+	switch cfg.Provider {
+	case config.AIProviderOpenAI:
+		return newOpenAIChatService(cfg)
+	case config.AIProviderOllama:
+		return newOllamaChatService(cfg), nil
+	case config.AIProviderFake:
+		return NewFakeChatService(cfg.Model), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", cfg.Provider)
+	}
+}
+
+func newEmbeddingService(cfg config.ModelConfig, dimensions int) (EmbeddingService, error) {
+	switch cfg.Provider {
+	case config.AIProviderOpenAI:
+		return newOpenAIEmbeddingService(cfg, dimensions), nil
+	case config.AIProviderOllama:
+		return newOllamaEmbeddingService(cfg, dimensions), nil
+	case config.AIProviderFake:
+		return NewFakeEmbeddingService(cfg.Model, dimensions), nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q", cfg.Provider)
+	}
+}
+
+// EmbedText 生成单段文本的向量，维度与配置不一致时返回错误，避免写入无法比较的向量
+func EmbedText(ctx context.Context, embedding EmbeddingService, text string) ([]float32, error) {
+	embeddings, err := embedding.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	if len(embeddings) == 0 {
+		return nil, fmt.Errorf("no embedding returned")
+	}
+	if len(embeddings[0]) != embedding.Dimensions() {
+		return nil, fmt.Errorf("model %s returned %d dimensions, expected %d (EMBEDDING_DIMENSIONS)", embedding.Model(), len(embeddings[0]), embedding.Dimensions())
+	}
+	return embeddings[0], nil
+}
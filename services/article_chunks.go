@@ -0,0 +1,104 @@
+package services
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"context"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+)
+
+// articleChunkTokenBudget 每个片段的 token 上限
+const articleChunkTokenBudget = 500
+
+// SyncArticleChunks 重新切分文章，只为内容哈希（含文章标题）变化或由其他模型生成向量的片段生成向量
+// 返回新生成向量的片段数
+func SyncArticleChunks(ctx context.Context, db *gorm.DB, embedding EmbeddingService, article models.Article) (int, error) {
+	if article.IsDeleted {
+		return 0, db.Where("article_id = ?", article.ID).Delete(&models.ArticleChunk{}).Error
+	}
+
+	var existing []models.ArticleChunk
+	if err := db.Select("id,content_hash,position,embedding_model,embedding_dimensions").Where("article_id = ?", article.ID).Find(&existing).Error; err != nil {
+		return 0, err
+	}
+	version := ActiveEmbeddingVersion(embedding)
+	reusable := make(map[string][]models.ArticleChunk, len(existing))
+	for _, chunk := range existing {
+		if version.Matches(chunk.EmbeddingModel, chunk.EmbeddingDimensions) {
+			reusable[chunk.ContentHash] = append(reusable[chunk.ContentHash], chunk)
+		}
+	}
+
+	type positionUpdate struct {
+		id       models.SnowflakeID
+		position int
+	}
+	var moved []positionUpdate
+	var created []models.ArticleChunk
+	kept := make(map[models.SnowflakeID]struct{}, len(existing))
+	for position, chunk := range common.ChunkMarkdown(article.Content, articleChunkTokenBudget) {
+		hash := chunk.Hash(article.Title)
+		if candidates := reusable[hash]; len(candidates) > 0 {
+			reused := candidates[0]
+			reusable[hash] = candidates[1:]
+			kept[reused.ID] = struct{}{}
+			if reused.Position != position {
+				moved = append(moved, positionUpdate{id: reused.ID, position: position})
+			}
+			continue
+		}
+
+		text := fmt.Sprintf("标题：%s\n内容：%s", article.Title, chunk.Content)
+		if chunk.HeadingPath != "" {
+			text = fmt.Sprintf("标题：%s\n章节：%s\n内容：%s", article.Title, chunk.HeadingPath, chunk.Content)
+		}
+		vector, err := EmbedText(ctx, embedding, text)
+		if err != nil {
+			return 0, fmt.Errorf("failed to embed chunk %d: %w", position, err)
+		}
+		created = append(created, models.ArticleChunk{
+			ArticleID:           article.ID,
+			Position:            position,
+			HeadingPath:         chunk.HeadingPath,
+			Content:             chunk.Content,
+			ContentHash:         hash,
+			TokenCount:          chunk.TokenCount,
+			Embedding:           pgvector.NewVector(vector),
+			EmbeddingModel:      version.Model,
+			EmbeddingDimensions: version.Dimensions,
+		})
+	}
+
+	stale := make([]models.SnowflakeID, 0)
+	for _, chunk := range existing {
+		if _, ok := kept[chunk.ID]; !ok {
+			stale = append(stale, chunk.ID)
+		}
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if len(stale) > 0 {
+			if err := tx.Where("id IN ?", stale).Delete(&models.ArticleChunk{}).Error; err != nil {
+				return err
+			}
+		}
+		for _, update := range moved {
+			if err := tx.Model(&models.ArticleChunk{}).Where("id = ?", update.id).Update("position", update.position).Error; err != nil {
+				return err
+			}
+		}
+		if len(created) > 0 {
+			return tx.Create(&created).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	log.Infof("Article %s chunks synced: %d new, %d kept, %d removed", article.ID, len(created), len(kept), len(stale))
+	return len(created), nil
+}
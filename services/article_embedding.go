@@ -0,0 +1,65 @@
+package services
+
+import (
+	"blog-server-go/models"
+	"context"
+	"fmt"
+
+	"github.com/pgvector/pgvector-go"
+	"gorm.io/gorm"
+)
+
+// EmbeddingVersion 生成向量的模型和维度，不同版本的向量不能互相比较
+type EmbeddingVersion struct {
+	Model      string `json:"model"`
+	Dimensions int    `json:"dimensions"`
+}
+
+// ActiveEmbeddingVersion 当前配置的向量模型版本，检索时只比较这个版本的向量
+func ActiveEmbeddingVersion(embedding EmbeddingService) EmbeddingVersion {
+	return EmbeddingVersion{Model: embedding.Model(), Dimensions: embedding.Dimensions()}
+}
+
+// Matches 判断已保存的向量是否由当前版本生成
+func (v EmbeddingVersion) Matches(model string, dimensions int) bool {
+	return v.Model == model && v.Dimensions == dimensions
+}
+
+// VectorColumn 将向量列转换为当前维度，与按维度建立的部分索引表达式一致
+// 维度来自配置而非用户输入，可以直接拼接到 SQL 中
+func (v EmbeddingVersion) VectorColumn(column string) string {
+	return fmt.Sprintf("%s::vector(%d)", column, v.Dimensions)
+}
+
+// EnsureEmbeddingIndex 为当前维度创建片段向量的 HNSW 部分索引
+func EnsureEmbeddingIndex(db *gorm.DB, version EmbeddingVersion) error {
+	return db.Exec(fmt.Sprintf(
+		"CREATE INDEX IF NOT EXISTS article_chunk_embedding_%d_idx ON article_chunk USING hnsw ((%s) vector_cosine_ops) WHERE embedding_dimensions = %d",
+		version.Dimensions, version.VectorColumn("embedding"), version.Dimensions,
+	)).Error
+}
+
+// articleEmbeddingText 合并标题和内容/摘要生成整篇文章的向量
+func articleEmbeddingText(article models.Article) string {
+	if article.Content != "" {
+		return fmt.Sprintf("标题：%s\n内容：%s", article.Title, article.Content)
+	}
+	if article.Summary != "" {
+		return fmt.Sprintf("标题：%s\n摘要：%s", article.Title, article.Summary)
+	}
+	return article.Title
+}
+
+// EmbedArticle 生成整篇文章的向量，并记录生成向量的模型和维度
+func EmbedArticle(ctx context.Context, db *gorm.DB, embedding EmbeddingService, article models.Article) error {
+	vector, err := EmbedText(ctx, embedding, articleEmbeddingText(article))
+	if err != nil {
+		return err
+	}
+	version := ActiveEmbeddingVersion(embedding)
+	return db.Model(&models.Article{}).Where("id = ?", article.ID).UpdateColumns(map[string]interface{}{
+		"embedding":            pgvector.NewVector(vector),
+		"embedding_model":      version.Model,
+		"embedding_dimensions": version.Dimensions,
+	}).Error
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"blog-server-go/common"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// metaDescriptionLimit SEO 描述的字数上限
+	metaDescriptionLimit = 160
+	// maxSuggestedTags 最多建议的标签数
+	maxSuggestedTags = 5
+	// maxSuggestedTitles 最多建议的备选标题数
+	maxSuggestedTitles = 3
+	// metadataTimeout 生成元数据建议的超时时间
+	metadataTimeout = time.Minute
+)
+
+// ErrNoMetadataSuggestion 模型没有返回可用的建议
+var ErrNoMetadataSuggestion = errors.New("model returned no metadata suggestion")
+
+const metadataPrompt = `你是博客编辑，请根据用户提供的文章草稿给出发布所需的元数据，并调用 suggest_metadata 返回：
+- tags：从已有标签中选择最相关的 1~5 个，不要创造新标签；没有合适的标签时返回空数组
+- metaDescription：用于搜索引擎结果的描述，一到两句话，不超过 160 字，包含文章的关键词
+- titles：3 个备选标题，准确概括文章内容，不要标题党
+- slug：文章链接，使用小写英文单词和连字符，不超过 8 个单词
+描述和标题使用文章的语言。
+
+已有标签：%s`
+
+// MetadataDraft 需要生成元数据建议的文章草稿
+type MetadataDraft struct {
+	Title   string   `json:"title"`
+	Content string   `json:"content"`
+	Tags    []string `json:"tags"`
+}
+
+// MetadataSuggestion 文章元数据建议，由管理员逐项采纳
+type MetadataSuggestion struct {
+	Tags            []string `json:"tags"`
+	MetaDescription string   `json:"metaDescription"`
+	Titles          []string `json:"titles"`
+	Slug            string   `json:"slug"`
+	Model           string   `json:"model"`
+}
+
+func metadataTool(vocabulary []string) *schema.ToolInfo {
+	// 空的枚举会被部分接口视为无效参数
+	var tagEnum []string
+	if len(vocabulary) > 0 {
+		tagEnum = vocabulary
+	}
+	return &schema.ToolInfo{
+		Name: "suggest_metadata",
+		Desc: "返回文章的标签、SEO 描述、备选标题和 slug",
+		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+			"tags": {
+				Type:     schema.Array,
+				Desc:     "从已有标签中选择的标签",
+				ElemInfo: &schema.ParameterInfo{Type: schema.String, Enum: tagEnum},
+				Required: true,
+			},
+			"metaDescription": {Type: schema.String, Desc: "SEO 描述，不超过 160 字", Required: true},
+			"titles": {
+				Type:     schema.Array,
+				Desc:     "备选标题",
+				ElemInfo: &schema.ParameterInfo{Type: schema.String},
+				Required: true,
+			},
+			"slug": {Type: schema.String, Desc: "小写英文单词和连字符组成的链接", Required: true},
+		}),
+	}
+}
+
+// SuggestArticleMetadata 通过工具调用获取结构化的元数据建议
+// 标签只保留已有标签中的项，描述截断到长度上限，slug 统一规范化
+func SuggestArticleMetadata(ctx context.Context, chat ChatService, draft MetadataDraft, vocabulary []string) (MetadataSuggestion, error) {
+	suggestion := MetadataSuggestion{Model: chat.Model()}
+	tagList := "（暂无）"
+	if len(vocabulary) > 0 {
+		tagList = strings.Join(vocabulary, "、")
+	}
+	user := fmt.Sprintf("标题：%s\n", draft.Title)
+	if len(draft.Tags) > 0 {
+		user += fmt.Sprintf("作者已选标签：%s\n", strings.Join(draft.Tags, "、"))
+	}
+	user += "\n" + common.TruncateUTF8(draft.Content, summaryInputLimit)
+	messages := []*schema.Message{
+		schema.SystemMessage(fmt.Sprintf(metadataPrompt, tagList)),
+		schema.UserMessage(user),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+	tool := metadataTool(vocabulary)
+	resp, err := chat.GenerateWithToolChoice(ctx, messages, []*schema.ToolInfo{tool}, schema.ToolChoiceForced)
+	if err != nil {
+		return suggestion, fmt.Errorf("failed to generate metadata: %w", err)
+	}
+
+	// 不支持强制调用工具的模型可能直接在回复中输出 JSON
+	raw := resp.Content
+	for _, call := range resp.ToolCalls {
+		if call.Function.Name == tool.Name {
+			raw = call.Function.Arguments
+			break
+		}
+	}
+	var result struct {
+		Tags            []string `json:"tags"`
+		MetaDescription string   `json:"metaDescription"`
+		Titles          []string `json:"titles"`
+		Slug            string   `json:"slug"`
+	}
+	start, end := strings.Index(raw, "{"), strings.LastIndex(raw, "}")
+	if start < 0 || end <= start {
+		return suggestion, ErrNoMetadataSuggestion
+	}
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &result); err != nil {
+		return suggestion, fmt.Errorf("%w: %v", ErrNoMetadataSuggestion, err)
+	}
+
+	known := make(map[string]string, len(vocabulary))
+	for _, name := range vocabulary {
+		known[strings.ToLower(name)] = name
+	}
+	suggestion.Tags = []string{}
+	picked := map[string]bool{}
+	for _, name := range common.NormalizeTagNames(result.Tags) {
+		canonical, ok := known[strings.ToLower(name)]
+		if !ok || picked[canonical] || len(suggestion.Tags) >= maxSuggestedTags {
+			continue
+		}
+		picked[canonical] = true
+		suggestion.Tags = append(suggestion.Tags, canonical)
+	}
+
+	suggestion.Titles = []string{}
+	seen := map[string]bool{}
+	for _, title := range result.Titles {
+		title = strings.TrimSpace(title)
+		if title == "" || seen[title] || len(suggestion.Titles) >= maxSuggestedTitles {
+			continue
+		}
+		seen[title] = true
+		suggestion.Titles = append(suggestion.Titles, title)
+	}
+
+	suggestion.MetaDescription = truncateSummary(result.MetaDescription, metaDescriptionLimit)
+	suggestion.Slug = common.Slugify(result.Slug)
+	if suggestion.MetaDescription == "" && len(suggestion.Titles) == 0 && suggestion.Slug == "" && len(suggestion.Tags) == 0 {
+		return suggestion, ErrNoMetadataSuggestion
+	}
+	return suggestion, nil
+}
@@ -0,0 +1,341 @@
+package services
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/meilisearch/meilisearch-go"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// ArticleSearchIndex 文章搜索使用的 Meilisearch 索引
+const ArticleSearchIndex = "blog"
+
+const (
+	articleIndexBatchSize  = 500
+	articleIndexPrimaryKey = "id"
+)
+
+// ArticleSearchDocument Meilisearch 中的文章文档
+// created_at 为 unix 秒，便于按范围过滤；view_count 只在文章变更或重建索引时更新
+type ArticleSearchDocument struct {
+	ID        string   `json:"id"`
+	Title     string   `json:"title"`
+	Content   string   `json:"content"`
+	Tag       string   `json:"tag"`
+	Tags      []string `json:"tags"`
+	CreatedAt int64    `json:"created_at"`
+	ViewCount int      `json:"view_count"`
+}
+
+// NewArticleSearchDocument 由文章生成搜索文档
+func NewArticleSearchDocument(article models.Article) ArticleSearchDocument {
+	return ArticleSearchDocument{
+		ID:        string(article.ID),
+		Title:     article.Title,
+		Content:   article.Content,
+		Tag:       article.Tag,
+		Tags:      common.SplitTagNames(article.Tag),
+		CreatedAt: article.CreatedAt.Unix(),
+		ViewCount: article.ViewCount,
+	}
+}
+
+// searchable 只有已发布且未删除的文章进入搜索索引
+func searchable(article models.Article) bool {
+	return !article.IsDeleted && article.Status == models.ArticleStatusPublished
+}
+
+// EnsureArticleIndex 创建索引（不存在时）并应用字段设置和管理员维护的搜索设置
+func EnsureArticleIndex(meili meilisearch.ServiceManager, uid string, settings SearchSettings) error {
+	if _, err := meili.GetIndex(uid); err != nil {
+		var meiliErr *meilisearch.Error
+		if !errors.As(err, &meiliErr) || meiliErr.StatusCode != http.StatusNotFound {
+			return err
+		}
+		taskInfo, err := meili.CreateIndex(&meilisearch.IndexConfig{Uid: uid, PrimaryKey: articleIndexPrimaryKey})
+		if err != nil {
+			return err
+		}
+		if err := waitForTask(meili, taskInfo); err != nil {
+			return err
+		}
+	}
+
+	rankingRules := settings.RankingRules
+	if len(rankingRules) == 0 {
+		rankingRules = DefaultRankingRules
+	}
+	index := meili.Index(uid)
+	taskInfo, err := index.UpdateSettings(&meilisearch.Settings{
+		RankingRules:         rankingRules,
+		SearchableAttributes: ArticleSearchableAttributes,
+		FilterableAttributes: ArticleFilterableAttributes,
+		SortableAttributes:   ArticleSortableAttributes,
+		StopWords:            settings.StopWords,
+		Synonyms:             settings.Synonyms,
+	})
+	if err != nil {
+		return err
+	}
+	if err := waitForTask(meili, taskInfo); err != nil {
+		return err
+	}
+
+	// 空值在 UpdateSettings 中会被忽略，需要显式重置
+	if len(settings.StopWords) == 0 {
+		if taskInfo, err = index.ResetStopWords(); err != nil {
+			return err
+		}
+		if err := waitForTask(meili, taskInfo); err != nil {
+			return err
+		}
+	}
+	if len(settings.Synonyms) == 0 {
+		if taskInfo, err = index.ResetSynonyms(); err != nil {
+			return err
+		}
+		return waitForTask(meili, taskInfo)
+	}
+	return nil
+}
+
+// PrepareArticleIndex 线上索引不存在时按当前设置创建，避免 Meilisearch 自动创建出没有过滤属性的索引
+// 已存在的索引由启动时的 MigrateArticleIndex 保证设置最新
+func PrepareArticleIndex(meili meilisearch.ServiceManager, rdb *redis.Client) error {
+	_, err := meili.GetIndex(ArticleSearchIndex)
+	if err == nil {
+		return nil
+	}
+	var meiliErr *meilisearch.Error
+	if !errors.As(err, &meiliErr) || meiliErr.StatusCode != http.StatusNotFound {
+		return err
+	}
+	settings, err := LoadSearchSettings(context.Background(), rdb)
+	if err != nil {
+		return err
+	}
+	return EnsureArticleIndex(meili, ArticleSearchIndex, settings)
+}
+
+// MigrateArticleIndex 启动时检查线上索引的字段设置，与代码中的定义不一致（或索引不存在）时全量重建
+// 新增过滤、排序字段时旧文档也缺少对应字段，只更新设置不够，因此直接重建
+func MigrateArticleIndex(meili meilisearch.ServiceManager, db *gorm.DB, rdb *redis.Client) error {
+	current, err := meili.Index(ArticleSearchIndex).GetSettings()
+	if err != nil {
+		var meiliErr *meilisearch.Error
+		if !errors.As(err, &meiliErr) || meiliErr.StatusCode != http.StatusNotFound {
+			return err
+		}
+	} else if slices.Equal(current.SearchableAttributes, ArticleSearchableAttributes) &&
+		sameAttributes(current.FilterableAttributes, ArticleFilterableAttributes) &&
+		sameAttributes(current.SortableAttributes, ArticleSortableAttributes) {
+		return nil
+	}
+
+	log.Infof("Search index %s settings are outdated, reindexing", ArticleSearchIndex)
+	count, err := ReindexArticles(meili, db, rdb)
+	if err != nil {
+		return err
+	}
+	log.Infof("Search index %s rebuilt with %d articles", ArticleSearchIndex, count)
+	return nil
+}
+
+// sameAttributes 比较两组字段，忽略顺序
+func sameAttributes(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// SyncArticleDocument 增量同步单篇文章：已发布则写入索引，否则从索引中删除
+func SyncArticleDocument(meili meilisearch.ServiceManager, db *gorm.DB, rdb *redis.Client, id string) error {
+	var article models.Article
+	result := db.Select("id,title,content,tag,status,is_deleted,created_at,view_count").Where("id = ?", id).Limit(1).Find(&article)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 || !searchable(article) {
+		_, err := meili.Index(ArticleSearchIndex).DeleteDocument(id, nil)
+		var meiliErr *meilisearch.Error
+		if errors.As(err, &meiliErr) && meiliErr.StatusCode == http.StatusNotFound {
+			return nil
+		}
+		return err
+	}
+
+	if err := PrepareArticleIndex(meili, rdb); err != nil {
+		return err
+	}
+	primaryKey := articleIndexPrimaryKey
+	_, err := meili.Index(ArticleSearchIndex).AddDocuments([]ArticleSearchDocument{NewArticleSearchDocument(article)}, &meilisearch.DocumentOptions{
+		PrimaryKey: &primaryKey,
+	})
+	return err
+}
+
+// ReindexArticles 在临时索引中全量重建，完成后与线上索引原子交换，重建期间搜索不受影响
+// 每次重建都会重新应用管理员维护的搜索设置
+func ReindexArticles(meili meilisearch.ServiceManager, db *gorm.DB, rdb *redis.Client) (int, error) {
+	settings, err := LoadSearchSettings(context.Background(), rdb)
+	if err != nil {
+		return 0, err
+	}
+	tempIndex := fmt.Sprintf("%s_reindex_%d", ArticleSearchIndex, time.Now().Unix())
+	if err := EnsureArticleIndex(meili, tempIndex, settings); err != nil {
+		return 0, err
+	}
+	// 无论成功与否都删除临时索引；交换成功后其中是旧数据
+	defer func() {
+		if taskInfo, err := meili.DeleteIndex(tempIndex); err != nil {
+			log.Errorf("Failed to delete temporary index %s: %v", tempIndex, err)
+		} else if err := waitForTask(meili, taskInfo); err != nil {
+			log.Errorf("Failed to delete temporary index %s: %v", tempIndex, err)
+		}
+	}()
+
+	count := 0
+	primaryKey := articleIndexPrimaryKey
+	var articles []models.Article
+	err = db.Where("is_deleted = ? AND status = ?", false, models.ArticleStatusPublished).
+		FindInBatches(&articles, articleIndexBatchSize, func(tx *gorm.DB, batch int) error {
+			documents := make([]ArticleSearchDocument, 0, len(articles))
+			for _, article := range articles {
+				documents = append(documents, NewArticleSearchDocument(article))
+			}
+			taskInfo, err := meili.Index(tempIndex).AddDocuments(documents, &meilisearch.DocumentOptions{PrimaryKey: &primaryKey})
+			if err != nil {
+				return err
+			}
+			if err := waitForTask(meili, taskInfo); err != nil {
+				return err
+			}
+			count += len(documents)
+			return nil
+		}).Error
+	if err != nil {
+		return 0, err
+	}
+
+	// 交换要求两个索引都存在
+	if err := PrepareArticleIndex(meili, rdb); err != nil {
+		return 0, err
+	}
+	taskInfo, err := meili.SwapIndexes([]*meilisearch.SwapIndexesParams{{Indexes: []string{ArticleSearchIndex, tempIndex}}})
+	if err != nil {
+		return 0, err
+	}
+	if err := waitForTask(meili, taskInfo); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ArticleIndexReport Postgres 与搜索索引的差异
+type ArticleIndexReport struct {
+	Expected   int      `json:"expected"`   // 应在索引中的已发布文章数
+	Indexed    int      `json:"indexed"`    // 索引中的文档数
+	Missing    []string `json:"missing"`    // 已发布但不在索引中
+	Unexpected []string `json:"unexpected"` // 在索引中但已删除、未发布或不存在
+	Outdated   []string `json:"outdated"`   // 标题、正文、标签或创建时间与数据库不一致
+	Consistent bool     `json:"consistent"`
+}
+
+// CheckArticleIndex 比较已发布文章与索引文档，报告缺失、多余和过期的文档
+func CheckArticleIndex(meili meilisearch.ServiceManager, db *gorm.DB) (*ArticleIndexReport, error) {
+	expected := make(map[string]string)
+	var articles []models.Article
+	err := db.Select("id,title,content,tag,created_at").Where("is_deleted = ? AND status = ?", false, models.ArticleStatusPublished).
+		FindInBatches(&articles, articleIndexBatchSize, func(tx *gorm.DB, batch int) error {
+			for _, article := range articles {
+				expected[string(article.ID)] = documentFingerprint(NewArticleSearchDocument(article))
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	indexed := make(map[string]string)
+	for offset := int64(0); ; offset += articleIndexBatchSize {
+		var result meilisearch.DocumentsResult
+		err := meili.Index(ArticleSearchIndex).GetDocuments(&meilisearch.DocumentsQuery{
+			Offset: offset,
+			Limit:  articleIndexBatchSize,
+			Fields: []string{"id", "title", "content", "tag", "tags", "created_at"},
+		}, &result)
+		if err != nil {
+			var meiliErr *meilisearch.Error
+			if errors.As(err, &meiliErr) && meiliErr.StatusCode == http.StatusNotFound {
+				break
+			}
+			return nil, err
+		}
+		var documents []ArticleSearchDocument
+		if err := result.Results.DecodeInto(&documents); err != nil {
+			return nil, err
+		}
+		for _, document := range documents {
+			indexed[document.ID] = documentFingerprint(document)
+		}
+		if len(documents) < articleIndexBatchSize {
+			break
+		}
+	}
+
+	report := &ArticleIndexReport{
+		Expected:   len(expected),
+		Indexed:    len(indexed),
+		Missing:    []string{},
+		Unexpected: []string{},
+		Outdated:   []string{},
+	}
+	for id, fingerprint := range expected {
+		indexedFingerprint, ok := indexed[id]
+		if !ok {
+			report.Missing = append(report.Missing, id)
+		} else if indexedFingerprint != fingerprint {
+			report.Outdated = append(report.Outdated, id)
+		}
+	}
+	for id := range indexed {
+		if _, ok := expected[id]; !ok {
+			report.Unexpected = append(report.Unexpected, id)
+		}
+	}
+	sort.Strings(report.Missing)
+	sort.Strings(report.Unexpected)
+	sort.Strings(report.Outdated)
+	report.Consistent = len(report.Missing) == 0 && len(report.Unexpected) == 0 && len(report.Outdated) == 0
+	return report, nil
+}
+
+// documentFingerprint 文档内容的摘要，用于比较数据库与索引是否一致；阅读量本就允许滞后，不参与比较
+func documentFingerprint(document ArticleSearchDocument) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%s\x00%d", document.Title, document.Content, document.Tag, document.CreatedAt)))
+	return hex.EncodeToString(sum[:])
+}
+
+func waitForTask(meili meilisearch.ServiceManager, taskInfo *meilisearch.TaskInfo) error {
+	task, err := meili.WaitForTask(taskInfo.TaskUID, 0)
+	if err != nil {
+		return err
+	}
+	if task.Status == meilisearch.TaskStatusFailed {
+		return fmt.Errorf("meilisearch task %d failed: %s", task.TaskUID, task.Error.Message)
+	}
+	return nil
+}
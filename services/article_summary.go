@@ -0,0 +1,228 @@
+package services
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// SummaryPromptVersion 摘要提示词的版本，修改 summaryPrompt 或长度限制时递增，已有摘要会在下次生成时更新
+	SummaryPromptVersion = 1
+	// ShortSummaryLimit 短摘要的字数上限
+	ShortSummaryLimit = 100
+	// LongSummaryLimit 长摘要的字数上限
+	LongSummaryLimit = 400
+	// summaryInputLimit 提交给模型的正文字节上限
+	summaryInputLimit = 48 * 1024
+	// summaryTimeout 单篇文章生成摘要的超时时间
+	summaryTimeout = time.Minute
+)
+
+const summaryPrompt = `你是博客编辑，请为用户提供的文章生成两段摘要，并调用 save_summary 保存：
+- short：一句话概括文章的主题和结论，不超过 100 字，用于文章列表和订阅源
+- long：一段话介绍文章的背景、主要内容和结论，不超过 400 字，用于文章详情页
+要求：使用文章的语言；只根据文章内容，不要编造；不要包含阅读时间、标题或"本文"之外的客套话。
+如果无法调用工具，直接输出 JSON：{"short": "...", "long": "..."}`
+
+var summaryTool = &schema.ToolInfo{
+	Name: "save_summary",
+	Desc: "保存文章的短摘要和长摘要",
+	ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
+		"short": {Type: schema.String, Desc: "一句话摘要，不超过 100 字", Required: true},
+		"long":  {Type: schema.String, Desc: "段落摘要，不超过 400 字", Required: true},
+	}),
+}
+
+// SummaryContentHash 正文的 sha256，正文未变化时不重新生成摘要
+func SummaryContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// summaryUpToDate 摘要是否由当前正文生成；手动编辑的摘要只看正文，不随提示词版本失效
+func summaryUpToDate(summary models.ArticleSummary, contentHash string) bool {
+	if summary.ContentHash != contentHash {
+		return false
+	}
+	return summary.Model == models.SummaryModelManual || summary.PromptVersion == SummaryPromptVersion
+}
+
+// GenerateArticleSummary 为文章生成短摘要和长摘要并保存
+// 已有摘要由相同正文和当前提示词版本生成时直接返回，force 为 true 时总是重新生成
+// 第二个返回值表示是否调用了模型
+func GenerateArticleSummary(ctx context.Context, db *gorm.DB, chat ChatService, article models.Article, force bool) (models.ArticleSummary, bool, error) {
+	contentHash := SummaryContentHash(article.Content)
+	var existing models.ArticleSummary
+	err := db.Where("article_id = ?", article.ID).Take(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return existing, false, err
+	}
+	if err == nil && !force && summaryUpToDate(existing, contentHash) {
+		return existing, false, nil
+	}
+	if strings.TrimSpace(article.Content) == "" {
+		return existing, false, fmt.Errorf("article %s has no content", article.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, summaryTimeout)
+	defer cancel()
+	short, long, err := requestSummary(ctx, chat, article)
+	if err != nil {
+		return existing, false, err
+	}
+
+	summary := models.ArticleSummary{
+		BaseModel:     existing.BaseModel,
+		ArticleID:     article.ID,
+		ShortSummary:  short,
+		LongSummary:   long,
+		Model:         chat.Model(),
+		PromptVersion: SummaryPromptVersion,
+		ContentHash:   contentHash,
+	}
+	summary.WordCount, summary.ReadingMinutes = common.ReadingStats(article.Content)
+	if err := SaveArticleSummary(db, &summary); err != nil {
+		return summary, true, err
+	}
+	return summary, true, nil
+}
+
+// requestSummary 通过工具调用获取结构化的摘要，模型不支持工具时解析回复中的 JSON
+func requestSummary(ctx context.Context, chat ChatService, article models.Article) (string, string, error) {
+	messages := []*schema.Message{
+		schema.SystemMessage(summaryPrompt),
+		schema.UserMessage(fmt.Sprintf("标题：%s\n\n%s", article.Title, common.TruncateUTF8(article.Content, summaryInputLimit))),
+	}
+	resp, err := chat.GenerateWithToolChoice(ctx, messages, []*schema.ToolInfo{summaryTool}, schema.ToolChoiceForced)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate summary: %w", err)
+	}
+
+	raw := resp.Content
+	for _, call := range resp.ToolCalls {
+		if call.Function.Name == summaryTool.Name {
+			raw = call.Function.Arguments
+			break
+		}
+	}
+	var result struct {
+		Short string `json:"short"`
+		Long  string `json:"long"`
+	}
+	if start, end := strings.Index(raw, "{"), strings.LastIndex(raw, "}"); start >= 0 && end > start {
+		if err := json.Unmarshal([]byte(raw[start:end+1]), &result); err != nil {
+			log.Warnf("Failed to parse summary of article %s: %v", article.ID, err)
+		}
+	}
+	// 没有结构化结果时把整段回复作为摘要
+	if result.Short == "" && result.Long == "" {
+		result.Short, result.Long = raw, raw
+	}
+	if result.Long == "" {
+		result.Long = result.Short
+	}
+	if result.Short == "" {
+		result.Short = result.Long
+	}
+
+	short := truncateSummary(result.Short, ShortSummaryLimit)
+	long := truncateSummary(result.Long, LongSummaryLimit)
+	if short == "" {
+		return "", "", fmt.Errorf("model returned an empty summary")
+	}
+	return short, long, nil
+}
+
+// truncateSummary 将摘要截断到 limit 个字符以内，尽量在句末截断，否则以省略号结尾
+func truncateSummary(text string, limit int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= limit {
+		return string(runes)
+	}
+	runes = runes[:limit]
+	for i := len(runes) - 1; i >= limit/2; i-- {
+		switch runes[i] {
+		case '。', '！', '？', '.', '!', '?':
+			return string(runes[:i+1])
+		}
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// SaveArticleSummary 按 article_id 写入或覆盖摘要
+func SaveArticleSummary(db *gorm.DB, summary *models.ArticleSummary) error {
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "article_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"updated_at", "short_summary", "long_summary", "reading_minutes", "word_count",
+			"model", "prompt_version", "content_hash",
+		}),
+	}).Create(summary).Error
+}
+
+// LoadArticleSummaries 批量读取文章摘要，没有摘要的文章不在结果中
+func LoadArticleSummaries(db *gorm.DB, ids []models.SnowflakeID) (map[models.SnowflakeID]models.ArticleSummary, error) {
+	summaries := make(map[models.SnowflakeID]models.ArticleSummary, len(ids))
+	if len(ids) == 0 {
+		return summaries, nil
+	}
+	var rows []models.ArticleSummary
+	if err := db.Where("article_id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		summaries[row.ArticleID] = row
+	}
+	return summaries, nil
+}
+
+// SummaryRegenerateResult 批量重新生成摘要的结果
+type SummaryRegenerateResult struct {
+	Total     int `json:"total"`
+	Generated int `json:"generated"`
+	Skipped   int `json:"skipped"` // 正文未变化或为空
+	Failed    int `json:"failed"`
+}
+
+// RegenerateArticleSummaries 为所有未删除的文章生成摘要，正文未变化的文章跳过
+func RegenerateArticleSummaries(ctx context.Context, db *gorm.DB, chat ChatService, force bool) (SummaryRegenerateResult, error) {
+	var result SummaryRegenerateResult
+	var articles []models.Article
+	if err := db.Select("id,title,content").Where("is_deleted", false).Order("id").Find(&articles).Error; err != nil {
+		return result, err
+	}
+	result.Total = len(articles)
+	for _, article := range articles {
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if strings.TrimSpace(article.Content) == "" {
+			result.Skipped++
+			continue
+		}
+		_, generated, err := GenerateArticleSummary(ctx, db, chat, article, force)
+		switch {
+		case err != nil:
+			result.Failed++
+			log.Errorf("Failed to generate summary of article %s: %v", article.ID, err)
+		case generated:
+			result.Generated++
+		default:
+			result.Skipped++
+		}
+	}
+	return result, nil
+}
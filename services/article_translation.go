@@ -0,0 +1,158 @@
+package services
+
+import (
+	"blog-server-go/common"
+	"blog-server-go/models"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// translationSectionTokens 每次翻译的章节 token 上限
+	translationSectionTokens = 1500
+	// translationAttempts 译文丢失占位符时重试的次数
+	translationAttempts = 2
+)
+
+var (
+	// ErrUnsupportedLocale 不支持翻译的语言
+	ErrUnsupportedLocale = errors.New("unsupported locale")
+	// ErrTranslationEdited 已有译文经过管理员编辑，未强制时不覆盖
+	ErrTranslationEdited = errors.New("translation has been edited")
+)
+
+// TranslationLanguages 支持翻译的语言及其在提示词中的名称
+var TranslationLanguages = map[string]string{
+	"en":    "English",
+	"ja":    "Japanese",
+	"ko":    "Korean",
+	"fr":    "French",
+	"de":    "German",
+	"es":    "Spanish",
+	"zh-TW": "Traditional Chinese (Taiwan)",
+}
+
+const translationPrompt = `你是技术博客的专业译者，请将用户提供的 Markdown 翻译为 %s。
+要求：
+- 保持 Markdown 结构（标题、列表、表格、强调）不变
+- ⟦0⟧ 这样的占位符代表代码或链接，每个占位符原样保留一次，放在译文中对应的位置
+- 通常不翻译的产品名、标识符和技术术语保持原文
+- 只输出译文，不要解释，也不要用代码块包裹`
+
+// NormalizeLocale 统一语言代码的大小写，例如 EN、zh-tw 转为 en、zh-TW；不支持的语言返回错误
+func NormalizeLocale(locale string) (string, error) {
+	parts := strings.SplitN(strings.TrimSpace(locale), "-", 2)
+	normalized := strings.ToLower(parts[0])
+	if len(parts) == 2 {
+		normalized += "-" + strings.ToUpper(parts[1])
+	}
+	if _, ok := TranslationLanguages[normalized]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedLocale, locale)
+	}
+	return normalized, nil
+}
+
+// MarkTranslationStale 原文在翻译之后更新过时标记为过期
+func MarkTranslationStale(translation *models.ArticleTranslation, article models.Article) {
+	translation.Stale = article.UpdatedAt.After(translation.SourceUpdatedAt)
+}
+
+// TranslateArticle 按章节机器翻译文章并保存，覆盖该语言已有的译文
+// 管理员编辑过的译文只有 force 为 true 时才覆盖，否则返回 ErrTranslationEdited
+// 代码块、行内代码和链接地址替换为占位符后再翻译，译文中缺失占位符时重试
+func TranslateArticle(ctx context.Context, db *gorm.DB, chat ChatService, article models.Article, locale string, force bool) (models.ArticleTranslation, error) {
+	translation := models.ArticleTranslation{ArticleID: article.ID, Locale: locale}
+	language, ok := TranslationLanguages[locale]
+	if !ok {
+		return translation, fmt.Errorf("%w: %s", ErrUnsupportedLocale, locale)
+	}
+
+	// 沿用已有译文的 ID；翻译前先检查，避免白白调用模型
+	var existing models.ArticleTranslation
+	err := db.Select("id,created_at,edited").Where("article_id = ? AND locale = ?", article.ID, locale).Take(&existing).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return translation, err
+	}
+	if existing.Edited && !force {
+		return translation, ErrTranslationEdited
+	}
+
+	title, err := translateMarkdown(ctx, chat, language, article.Title)
+	if err != nil {
+		return translation, fmt.Errorf("failed to translate title: %w", err)
+	}
+	sections := common.SplitMarkdownSections(article.Content, translationSectionTokens)
+	translated := make([]string, 0, len(sections))
+	for i, section := range sections {
+		text, err := translateMarkdown(ctx, chat, language, section)
+		if err != nil {
+			return translation, fmt.Errorf("failed to translate section %d/%d: %w", i+1, len(sections), err)
+		}
+		translated = append(translated, text)
+	}
+
+	translation.BaseModel = existing.BaseModel
+	translation.Title = strings.TrimSpace(title)
+	translation.Content = strings.Join(translated, "\n\n")
+	translation.Model = chat.Model()
+	translation.SourceUpdatedAt = article.UpdatedAt
+	if err := SaveArticleTranslation(db, &translation); err != nil {
+		return translation, err
+	}
+	return translation, nil
+}
+
+// translateMarkdown 翻译一段 Markdown，只有代码和链接的段落原样返回
+func translateMarkdown(ctx context.Context, chat ChatService, language string, text string) (string, error) {
+	protected, kept := common.ProtectMarkdown(text)
+	if common.OnlyPlaceholders(protected) {
+		return text, nil
+	}
+	messages := []*schema.Message{
+		schema.SystemMessage(fmt.Sprintf(translationPrompt, language)),
+		schema.UserMessage(protected),
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < translationAttempts; attempt++ {
+		result, err := chat.GenerateText(ctx, messages)
+		if err != nil {
+			return "", err
+		}
+		restored, err := common.RestoreMarkdown(unwrapCodeFence(result), kept)
+		if err == nil {
+			return restored, nil
+		}
+		lastErr = err
+	}
+	return "", fmt.Errorf("translation changed protected content: %w", lastErr)
+}
+
+// unwrapCodeFence 去掉模型给整段译文加上的代码块；原文中的代码块已替换为占位符，译文中不应出现 ```
+func unwrapCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) < 2 {
+		return text
+	}
+	return strings.TrimSpace(strings.Join(lines[1:len(lines)-1], "\n"))
+}
+
+// SaveArticleTranslation 按文章和语言写入或覆盖译文
+func SaveArticleTranslation(db *gorm.DB, translation *models.ArticleTranslation) error {
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "article_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"updated_at", "title", "content", "model", "edited", "source_updated_at",
+		}),
+	}).Create(translation).Error
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"blog-server-go/models"
+	"os"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// defaultTrashRetentionDays 回收站默认保留天数
+const defaultTrashRetentionDays = 30
+
+// TrashRetentionDays 回收站保留天数，通过 ARTICLE_TRASH_RETENTION_DAYS 配置，<=0 表示不自动清理
+func TrashRetentionDays() int {
+	value := os.Getenv("ARTICLE_TRASH_RETENTION_DAYS")
+	if value == "" {
+		return defaultTrashRetentionDays
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultTrashRetentionDays
+	}
+	return days
+}
+
+// TrashPurgeAt 回收站中文章的自动清理时间，不自动清理时返回 nil
+func TrashPurgeAt(trashedAt *time.Time) *time.Time {
+	days := TrashRetentionDays()
+	if trashedAt == nil || days <= 0 {
+		return nil
+	}
+	purgeAt := trashedAt.AddDate(0, 0, days)
+	return &purgeAt
+}
+
+// PurgeArticles 永久删除回收站中的文章及其标签、系列、slug 跳转、修订记录和 RAG 片段
+// 只会删除 is_deleted = true 的文章，返回实际删除的文章 ID
+func PurgeArticles(db *gorm.DB, ids []models.SnowflakeID) ([]models.SnowflakeID, error) {
+	var purged []models.SnowflakeID
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Article{}).Where("id IN ? AND is_deleted = ?", ids, true).Pluck("id", &purged).Error; err != nil {
+			return err
+		}
+		if len(purged) == 0 {
+			return nil
+		}
+		if err := tx.Where("article_id IN ?", purged).Delete(&models.ArticleTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id IN ?", purged).Delete(&models.SeriesArticle{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id IN ?", purged).Delete(&models.ArticleSlugRedirect{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id IN ?", purged).Delete(&models.ArticleRevision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id IN ?", purged).Delete(&models.ArticleChunk{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id IN ?", purged).Delete(&models.ArticleSummary{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("article_id IN ?", purged).Delete(&models.ArticleTranslation{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", purged).Delete(&models.Article{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return purged, nil
+}
+
+// ExpiredTrashArticleIDs 在回收站中超过保留天数的文章
+func ExpiredTrashArticleIDs(db *gorm.DB, now time.Time) ([]models.SnowflakeID, error) {
+	days := TrashRetentionDays()
+	if days <= 0 {
+		return nil, nil
+	}
+	var ids []models.SnowflakeID
+	err := db.Model(&models.Article{}).
+		Where("is_deleted = ? AND trashed_at <= ?", true, now.AddDate(0, 0, -days)).
+		Pluck("id", &ids).Error
+	return ids, err
+}
@@ -0,0 +1,417 @@
+package services
+
+import (
+	"blog-server-go/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// embeddingJobKey 任务进度（JSON），重启后据此继续
+	embeddingJobKey = "embeddingJob"
+	// embeddingJobLockKey 保证多个实例中只有一个在执行任务
+	embeddingJobLockKey = "embeddingJob:lock"
+	embeddingJobLockTTL = 5 * time.Minute
+	embeddingJobBatch   = 20
+	// embeddingJobMaxConsecutiveFailures 连续失败次数达到上限时停止任务，通常是模型服务不可用或维度配置错误
+	embeddingJobMaxConsecutiveFailures = 5
+	// DefaultEmbeddingJobRate 默认每分钟的向量请求数
+	DefaultEmbeddingJobRate = 60
+	maxEmbeddingJobRate     = 6000
+)
+
+// 任务状态
+const (
+	EmbeddingJobIdle      = "idle"
+	EmbeddingJobRunning   = "running"
+	EmbeddingJobPaused    = "paused"
+	EmbeddingJobCompleted = "completed"
+	EmbeddingJobFailed    = "failed"
+)
+
+var (
+	// ErrEmbeddingJobRunning 已有任务在执行
+	ErrEmbeddingJobRunning = errors.New("embedding job is already running")
+	// ErrEmbeddingJobNotRunning 没有正在执行的任务
+	ErrEmbeddingJobNotRunning = errors.New("embedding job is not running")
+)
+
+// EmbeddingJobProgress 重新生成向量任务的进度
+type EmbeddingJobProgress struct {
+	Status        string           `json:"status"`
+	Version       EmbeddingVersion `json:"version"`
+	RatePerMinute int              `json:"ratePerMinute"`
+	Total         int64            `json:"total"`     // 开始时未删除的文章数
+	Processed     int64            `json:"processed"` // 已处理的文章数，包含跳过和失败的文章
+	Embedded      int64            `json:"embedded"`  // 重新生成了向量的文章数
+	Skipped       int64            `json:"skipped"`   // 已是当前版本，无需处理的文章数
+	Failed        int64            `json:"failed"`
+	Cursor        string           `json:"cursor"` // 最后处理的文章 ID，继续时从下一篇开始
+	LastError     string           `json:"lastError,omitempty"`
+	StartedAt     *time.Time       `json:"startedAt,omitempty"`
+	UpdatedAt     *time.Time       `json:"updatedAt,omitempty"`
+	FinishedAt    *time.Time       `json:"finishedAt,omitempty"`
+	// 以下为查询时实时统计，不保存
+	StaleArticles int64 `json:"staleArticles"` // 向量不是当前版本的文章数
+	StaleChunks   int64 `json:"staleChunks"`   // 向量不是当前版本的片段数
+}
+
+// EmbeddingJob 按文章 ID 顺序为所有文章重新生成整篇向量和片段向量
+// 进度保存在 Redis 中，暂停或进程重启后可以从上次的位置继续；向量请求按每分钟次数限流
+type EmbeddingJob struct {
+	db        *gorm.DB
+	rdb       *redis.Client
+	embedding EmbeddingService
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewEmbeddingJob 创建重新生成向量的任务
+func NewEmbeddingJob(db *gorm.DB, rdb *redis.Client, embedding EmbeddingService) *EmbeddingJob {
+	return &EmbeddingJob{db: db, rdb: rdb, embedding: embedding}
+}
+
+// Progress 读取任务进度，并统计当前仍需处理的文章和片段数
+func (job *EmbeddingJob) Progress(ctx context.Context) (*EmbeddingJobProgress, error) {
+	progress, err := job.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version := ActiveEmbeddingVersion(job.embedding)
+	if err := job.staleArticles(version).Count(&progress.StaleArticles).Error; err != nil {
+		return nil, err
+	}
+	err = job.db.Model(&models.ArticleChunk{}).
+		Where("embedding_model <> ? OR embedding_dimensions <> ?", version.Model, version.Dimensions).
+		Count(&progress.StaleChunks).Error
+	if err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// Start 开始或继续任务；上次的任务未完成且模型版本相同时从上次的位置继续，restart 为 true 时从头开始
+func (job *EmbeddingJob) Start(ratePerMinute int, restart bool) (*EmbeddingJobProgress, error) {
+	ctx := context.Background()
+	if ratePerMinute <= 0 {
+		ratePerMinute = DefaultEmbeddingJobRate
+	}
+	ratePerMinute = min(ratePerMinute, maxEmbeddingJobRate)
+
+	locked, err := job.rdb.SetNX(ctx, embeddingJobLockKey, time.Now().Unix(), embeddingJobLockTTL).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, ErrEmbeddingJobRunning
+	}
+
+	progress, err := job.prepare(ctx, ratePerMinute, restart)
+	if err != nil {
+		job.rdb.Del(ctx, embeddingJobLockKey)
+		return nil, err
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	job.mu.Lock()
+	job.cancel = cancel
+	job.mu.Unlock()
+	go job.run(runCtx, progress)
+	return progress, nil
+}
+
+// prepare 生成本次执行的初始进度并保存
+func (job *EmbeddingJob) prepare(ctx context.Context, ratePerMinute int, restart bool) (*EmbeddingJobProgress, error) {
+	version := ActiveEmbeddingVersion(job.embedding)
+	if err := EnsureEmbeddingIndex(job.db, version); err != nil {
+		// 维度超过 HNSW 的上限时无法建索引，检索退化为顺序扫描但结果仍然正确
+		log.Warnf("Failed to create embedding index for %d dimensions: %v", version.Dimensions, err)
+	}
+
+	progress, err := job.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resumable := progress.Status != EmbeddingJobIdle && progress.Status != EmbeddingJobCompleted && progress.Version == version
+	now := time.Now()
+	if restart || !resumable {
+		progress = &EmbeddingJobProgress{Version: version, StartedAt: &now}
+		if err := job.db.Model(&models.Article{}).Where("is_deleted = ?", false).Count(&progress.Total).Error; err != nil {
+			return nil, err
+		}
+	}
+	progress.Status = EmbeddingJobRunning
+	progress.RatePerMinute = ratePerMinute
+	progress.LastError = ""
+	progress.FinishedAt = nil
+	return progress, job.save(ctx, progress)
+}
+
+// Pause 暂停任务，已处理的进度保留，之后可以调用 Start 继续
+func (job *EmbeddingJob) Pause() (*EmbeddingJobProgress, error) {
+	ctx := context.Background()
+	progress, err := job.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if progress.Status != EmbeddingJobRunning {
+		return nil, ErrEmbeddingJobNotRunning
+	}
+	// 任务可能运行在其他实例上，通过状态通知其在处理完当前文章后停止
+	progress.Status = EmbeddingJobPaused
+	if err := job.save(ctx, progress); err != nil {
+		return nil, err
+	}
+	job.mu.Lock()
+	if job.cancel != nil {
+		job.cancel()
+	}
+	job.mu.Unlock()
+	return progress, nil
+}
+
+// ResumeInterrupted 启动时继续因进程退出而中断的任务
+// 进程刚启动时不可能有任务在执行，状态为运行中说明上次异常退出，锁也已失效，先释放锁再继续；
+// 继续失败时将状态改为暂停，由管理员手动继续或重新开始
+func (job *EmbeddingJob) ResumeInterrupted() {
+	ctx := context.Background()
+	progress, err := job.load(ctx)
+	if err != nil {
+		log.Errorf("Failed to load embedding job: %v", err)
+		return
+	}
+	if progress.Status != EmbeddingJobRunning {
+		return
+	}
+	if err := job.rdb.Del(ctx, embeddingJobLockKey).Err(); err != nil {
+		log.Errorf("Failed to release stale embedding job lock: %v", err)
+	}
+	if _, err := job.Start(progress.RatePerMinute, false); err != nil {
+		log.Warnf("Failed to resume embedding job: %v", err)
+		if errors.Is(err, ErrEmbeddingJobRunning) {
+			return
+		}
+		now := time.Now()
+		progress.Status = EmbeddingJobPaused
+		progress.LastError = err.Error()
+		progress.UpdatedAt = &now
+		if err := job.save(ctx, progress); err != nil {
+			log.Errorf("Failed to save embedding job progress: %v", err)
+		}
+		return
+	}
+	log.Infof("Embedding job resumed from article %s", progress.Cursor)
+}
+
+func (job *EmbeddingJob) run(ctx context.Context, progress *EmbeddingJobProgress) {
+	defer job.rdb.Del(context.Background(), embeddingJobLockKey)
+	defer func() {
+		job.mu.Lock()
+		job.cancel = nil
+		job.mu.Unlock()
+	}()
+
+	limiter := newRateLimitedEmbedding(job.embedding, progress.RatePerMinute)
+	defer limiter.Stop()
+
+	consecutiveFailures := 0
+	for {
+		var articles []models.Article
+		query := job.db.Select("id,title,content,is_deleted,embedding_model,embedding_dimensions").
+			Where("is_deleted = ?", false).Order("id ASC").Limit(embeddingJobBatch)
+		if progress.Cursor != "" {
+			query = query.Where("id > ?", progress.Cursor)
+		}
+		if err := query.Find(&articles).Error; err != nil {
+			job.finish(progress, EmbeddingJobFailed, err)
+			return
+		}
+		if len(articles) == 0 {
+			// 片段向量变化后相关文章需要重新计算
+			job.rdb.Del(context.Background(), "articleRelated")
+			job.finish(progress, EmbeddingJobCompleted, nil)
+			return
+		}
+
+		for _, article := range articles {
+			if job.stopped(ctx) {
+				return
+			}
+			embedded, err := job.embedArticle(ctx, limiter, article)
+			if job.stopped(ctx) {
+				// 暂停时正在处理的文章不计入进度，继续时重新处理
+				return
+			}
+			progress.Processed++
+			progress.Cursor = string(article.ID)
+			switch {
+			case err != nil:
+				log.Errorf("Failed to re-embed article %s: %v", article.ID, err)
+				progress.Failed++
+				progress.LastError = fmt.Sprintf("article %s: %v", article.ID, err)
+				consecutiveFailures++
+			case embedded:
+				progress.Embedded++
+				consecutiveFailures = 0
+			default:
+				progress.Skipped++
+				consecutiveFailures = 0
+			}
+			if consecutiveFailures >= embeddingJobMaxConsecutiveFailures {
+				job.finish(progress, EmbeddingJobFailed, errors.New(progress.LastError))
+				return
+			}
+			now := time.Now()
+			progress.UpdatedAt = &now
+			saved, err := job.saveIfRunning(context.Background(), progress)
+			if err != nil {
+				log.Errorf("Failed to save embedding job progress: %v", err)
+			} else if !saved {
+				// 保存前任务已被暂停
+				return
+			}
+			job.rdb.Expire(context.Background(), embeddingJobLockKey, embeddingJobLockTTL)
+		}
+	}
+}
+
+// embedArticle 重新生成一篇文章的整篇向量和片段向量，都已是当前版本时返回 false
+func (job *EmbeddingJob) embedArticle(ctx context.Context, embedding EmbeddingService, article models.Article) (bool, error) {
+	embedded := false
+	if !ActiveEmbeddingVersion(embedding).Matches(article.EmbeddingModel, article.EmbeddingDimensions) {
+		if err := EmbedArticle(ctx, job.db, embedding, article); err != nil {
+			return false, err
+		}
+		embedded = true
+	}
+	created, err := SyncArticleChunks(ctx, job.db, embedding, article)
+	if err != nil {
+		return embedded, err
+	}
+	return embedded || created > 0, nil
+}
+
+// stopped 本地取消或其他实例将状态改为暂停时停止
+func (job *EmbeddingJob) stopped(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	current, err := job.load(context.Background())
+	return err == nil && current.Status != EmbeddingJobRunning
+}
+
+func (job *EmbeddingJob) finish(progress *EmbeddingJobProgress, status string, err error) {
+	now := time.Now()
+	progress.Status = status
+	progress.UpdatedAt = &now
+	progress.FinishedAt = &now
+	if err != nil {
+		progress.LastError = err.Error()
+		log.Errorf("Embedding job stopped: %v", err)
+	} else {
+		log.Infof("Embedding job completed: %d embedded, %d skipped, %d failed", progress.Embedded, progress.Skipped, progress.Failed)
+	}
+	if _, err := job.saveIfRunning(context.Background(), progress); err != nil {
+		log.Errorf("Failed to save embedding job progress: %v", err)
+	}
+}
+
+// staleArticles 未删除且向量不是当前版本的文章
+func (job *EmbeddingJob) staleArticles(version EmbeddingVersion) *gorm.DB {
+	return job.db.Model(&models.Article{}).
+		Where("is_deleted = ?", false).
+		Where("embedding_model <> ? OR embedding_dimensions <> ?", version.Model, version.Dimensions)
+}
+
+func (job *EmbeddingJob) load(ctx context.Context) (*EmbeddingJobProgress, error) {
+	progress := &EmbeddingJobProgress{Status: EmbeddingJobIdle, Version: ActiveEmbeddingVersion(job.embedding)}
+	raw, err := job.rdb.Get(ctx, embeddingJobKey).Result()
+	if errors.Is(err, redis.Nil) {
+		return progress, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(raw), progress); err != nil {
+		return nil, err
+	}
+	progress.StaleArticles, progress.StaleChunks = 0, 0
+	return progress, nil
+}
+
+func (job *EmbeddingJob) save(ctx context.Context, progress *EmbeddingJobProgress) error {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	return job.rdb.Set(ctx, embeddingJobKey, data, 0).Err()
+}
+
+// saveIfRunning 重新读取状态，只在任务仍在运行时保存进度，避免覆盖 Pause 写入的暂停状态
+// 读取与写入之间状态被修改时同样不保存
+func (job *EmbeddingJob) saveIfRunning(ctx context.Context, progress *EmbeddingJobProgress) (bool, error) {
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return false, err
+	}
+	saved := false
+	err = job.rdb.Watch(ctx, func(tx *redis.Tx) error {
+		raw, err := tx.Get(ctx, embeddingJobKey).Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		var current EmbeddingJobProgress
+		if raw != "" {
+			if err := json.Unmarshal([]byte(raw), &current); err != nil {
+				return err
+			}
+		}
+		if current.Status != EmbeddingJobRunning {
+			return nil
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, embeddingJobKey, data, 0)
+			return nil
+		})
+		saved = err == nil
+		return err
+	}, embeddingJobKey)
+	if errors.Is(err, redis.TxFailedErr) {
+		return false, nil
+	}
+	return saved, err
+}
+
+// rateLimitedEmbedding 按固定间隔发出向量请求
+type rateLimitedEmbedding struct {
+	EmbeddingService
+	ticker *time.Ticker
+}
+
+func newRateLimitedEmbedding(embedding EmbeddingService, ratePerMinute int) *rateLimitedEmbedding {
+	return &rateLimitedEmbedding{EmbeddingService: embedding, ticker: time.NewTicker(time.Minute / time.Duration(ratePerMinute))}
+}
+
+// Embed 等待下一个时间间隔后再请求
+func (r *rateLimitedEmbedding) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-r.ticker.C:
+	}
+	return r.EmbeddingService.Embed(ctx, texts)
+}
+
+// Stop 释放计时器
+func (r *rateLimitedEmbedding) Stop() {
+	r.ticker.Stop()
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"blog-server-go/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// embeddingRequestTimeout 单次向量请求的超时时间，调用方的 ctx 更短时以 ctx 为准
+const embeddingRequestTimeout = 30 * time.Second
+
+// openAIEmbeddingService OpenAI 兼容的 /embeddings 接口（llama.cpp、vLLM 等）
+type openAIEmbeddingService struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	dimensions int
+	client     *http.Client
+}
+
+func newOpenAIEmbeddingService(cfg config.ModelConfig, dimensions int) *openAIEmbeddingService {
+	return &openAIEmbeddingService{
+		baseURL:    cfg.BaseURL,
+		apiKey:     cfg.APIKey,
+		model:      cfg.Model,
+		dimensions: dimensions,
+		client:     &http.Client{Timeout: embeddingRequestTimeout},
+	}
+}
+
+func (s *openAIEmbeddingService) Model() string {
+	return s.model
+}
+
+func (s *openAIEmbeddingService) Dimensions() int {
+	return s.dimensions
+}
+
+// Embed 调用 /embeddings 接口批量生成向量
+func (s *openAIEmbeddingService) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	jsonBody, err := json.Marshal(map[string]interface{}{
+		"model": s.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/embeddings", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %s", string(respBody))
+	}
+
+	var result struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(result.Data))
+	}
+
+	// 接口不保证按输入顺序返回，按 index 排序
+	sort.SliceStable(result.Data, func(i, j int) bool { return result.Data[i].Index < result.Data[j].Index })
+	embeddings := make([][]float32, 0, len(result.Data))
+	for _, item := range result.Data {
+		embeddings = append(embeddings, item.Embedding)
+	}
+	return embeddings, nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+const (
+	// fakeStreamChunkRunes 假对话模型流式输出时每段的字符数
+	fakeStreamChunkRunes = 8
+	// fakeEmbeddingDimensions 未指定维度时与 article_chunk.embedding 一致
+	fakeEmbeddingDimensions = 1024
+)
+
+// FakeChatService 确定性的假对话模型：复述最后一条用户消息，只在强制调用工具时调用第一个工具
+type FakeChatService struct {
+	model string
+}
+
+// NewFakeChatService 创建假对话模型
+func NewFakeChatService(modelName string) *FakeChatService {
+	if modelName == "" {
+		modelName = "fake"
+	}
+	return &FakeChatService{model: modelName}
+}
+
+func (s *FakeChatService) Model() string {
+	return s.model
+}
+
+func (s *FakeChatService) reply(messages []*schema.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == schema.User {
+			return fmt.Sprintf("[%s] %s", s.model, messages[i].Content)
+		}
+	}
+	return fmt.Sprintf("[%s]", s.model)
+}
+
+// GenerateText 返回固定格式的回复
+func (s *FakeChatService) GenerateText(ctx context.Context, messages []*schema.Message, opts ...model.Option) (string, error) {
+	return s.reply(messages), nil
+}
+
+// GenerateStream 将回复按固定长度分段回调
+func (s *FakeChatService) GenerateStream(ctx context.Context, messages []*schema.Message, callback func(chunk string) error, opts ...model.Option) error {
+	runes := []rune(s.reply(messages))
+	for start := 0; start < len(runes); start += fakeStreamChunkRunes {
+		end := min(start+fakeStreamChunkRunes, len(runes))
+		if err := callback(string(runes[start:end])); err != nil {
+			return fmt.Errorf("callback error: %w", err)
+		}
+	}
+	return nil
+}
+
+// GenerateWithTools 不调用工具，直接回复
+func (s *FakeChatService) GenerateWithTools(ctx context.Context, messages []*schema.Message, tools []*schema.ToolInfo) (*schema.Message, error) {
+	return schema.AssistantMessage(s.reply(messages), nil), nil
+}
+
+// GenerateWithToolChoice 强制调用工具时按第一个工具的参数定义生成调用，否则直接回复
+func (s *FakeChatService) GenerateWithToolChoice(ctx context.Context, messages []*schema.Message, tools []*schema.ToolInfo, choice schema.ToolChoice) (*schema.Message, error) {
+	if choice != schema.ToolChoiceForced || len(tools) == 0 {
+		return schema.AssistantMessage(s.reply(messages), nil), nil
+	}
+	tool := tools[0]
+	arguments, err := s.toolArguments(tool, s.reply(messages))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build arguments of tool %s: %w", tool.Name, err)
+	}
+	return schema.AssistantMessage("", []schema.ToolCall{{
+		ID:       "fake-" + tool.Name,
+		Type:     "function",
+		Function: schema.FunctionCall{Name: tool.Name, Arguments: arguments},
+	}}), nil
+}
+
+// toolArguments 按工具参数的 JSON Schema 生成参数：字符串使用回复文本，有枚举时取第一个值，数组只有一个元素
+func (s *FakeChatService) toolArguments(tool *schema.ToolInfo, text string) (string, error) {
+	if tool.ParamsOneOf == nil {
+		return "{}", nil
+	}
+	jsonSchema, err := tool.ParamsOneOf.ToJSONSchema()
+	if err != nil {
+		return "", err
+	}
+	raw, err := json.Marshal(jsonSchema)
+	if err != nil {
+		return "", err
+	}
+	var definition map[string]any
+	if err := json.Unmarshal(raw, &definition); err != nil {
+		return "", err
+	}
+	arguments, err := json.Marshal(fakeSchemaValue(definition, text))
+	if err != nil {
+		return "", err
+	}
+	return string(arguments), nil
+}
+
+func fakeSchemaValue(definition map[string]any, text string) any {
+	if enum, ok := definition["enum"].([]any); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	switch definition["type"] {
+	case string(schema.Object):
+		value := map[string]any{}
+		properties, _ := definition["properties"].(map[string]any)
+		for name, property := range properties {
+			if property, ok := property.(map[string]any); ok {
+				value[name] = fakeSchemaValue(property, text)
+			}
+		}
+		return value
+	case string(schema.Array):
+		items, ok := definition["items"].(map[string]any)
+		if !ok {
+			return []any{}
+		}
+		return []any{fakeSchemaValue(items, text)}
+	case string(schema.Integer), string(schema.Number):
+		return 0
+	case string(schema.Boolean):
+		return false
+	case string(schema.Null):
+		return nil
+	default:
+		return text
+	}
+}
+
+// FakeEmbeddingService 确定性的假向量模型：将字符二元组哈希到各维度后归一化，
+// 相同文本得到相同向量，字面相近的文本余弦相似度也较高
+type FakeEmbeddingService struct {
+	model      string
+	dimensions int
+}
+
+// NewFakeEmbeddingService 创建假向量模型
+func NewFakeEmbeddingService(modelName string, dimensions int) *FakeEmbeddingService {
+	if modelName == "" {
+		modelName = "fake"
+	}
+	if dimensions <= 0 {
+		dimensions = fakeEmbeddingDimensions
+	}
+	return &FakeEmbeddingService{model: modelName, dimensions: dimensions}
+}
+
+func (s *FakeEmbeddingService) Model() string {
+	return s.model
+}
+
+func (s *FakeEmbeddingService) Dimensions() int {
+	return s.dimensions
+}
+
+// Embed 生成确定性的向量
+func (s *FakeEmbeddingService) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(texts))
+	for _, text := range texts {
+		embeddings = append(embeddings, s.embed(text))
+	}
+	return embeddings, nil
+}
+
+func (s *FakeEmbeddingService) embed(text string) []float32 {
+	vector := make([]float32, s.dimensions)
+	var runes []rune
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			runes = append(runes, r)
+		}
+	}
+	if len(runes) == 1 {
+		runes = append(runes, ' ')
+	}
+	for i := 0; i+1 < len(runes); i++ {
+		h := fnv.New32a()
+		h.Write([]byte(string(runes[i : i+2])))
+		sum := h.Sum32()
+		// 最高位决定符号，降低不相关文本的相似度
+		if sum&(1<<31) != 0 {
+			vector[int(sum%uint32(s.dimensions))] -= 1
+		} else {
+			vector[int(sum%uint32(s.dimensions))] += 1
+		}
+	}
+
+	var norm float64
+	for _, v := range vector {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return vector
+	}
+	norm = math.Sqrt(norm)
+	for i := range vector {
+		vector[i] = float32(float64(vector[i]) / norm)
+	}
+	return vector
+}
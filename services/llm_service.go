@@ -1,12 +1,12 @@
 package services
 
 import (
+	"blog-server-go/config"
 	"bytes"
 	"context"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
@@ -34,35 +34,39 @@ func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error)
 	return resp, err
 }
 
-// LLMService LLM 服务封装
-type LLMService struct {
+// openAIChatService OpenAI 兼容接口的对话模型
+type openAIChatService struct {
 	chatModel model.ChatModel
+	model     string
 }
 
-// NewLLMService 创建 LLM 服务实例
-func NewLLMService() *LLMService {
-	config := &openaicomp.ChatModelConfig{
-		BaseURL: "https://llm.ooxo.cc/v1",
-		APIKey:  os.Getenv("OPENROUTER_API_KEY"),
-		Model:   "qwen2.5-1.5b-instruct",
+// newOpenAIChatService 创建 OpenAI 兼容接口的对话模型
+func newOpenAIChatService(cfg config.ModelConfig) (*openAIChatService, error) {
+	chatModel, err := openaicomp.NewChatModel(context.Background(), &openaicomp.ChatModelConfig{
+		BaseURL: cfg.BaseURL,
+		APIKey:  cfg.APIKey,
+		Model:   cfg.Model,
 		HTTPClient: &http.Client{
 			Transport: &loggingTransport{transport: http.DefaultTransport},
 		},
-	}
-
-	chatModel, err := openaicomp.NewChatModel(context.Background(), config)
+	})
 	if err != nil {
-		panic(fmt.Sprintf("Failed to create chat model: %v", err))
+		return nil, fmt.Errorf("failed to create chat model: %w", err)
 	}
 
-	return &LLMService{
+	return &openAIChatService{
 		chatModel: chatModel,
-	}
+		model:     cfg.Model,
+	}, nil
+}
+
+func (s *openAIChatService) Model() string {
+	return s.model
 }
 
 // GenerateText 生成文本（非流式）
-func (s *LLMService) GenerateText(ctx context.Context, messages []*schema.Message) (string, error) {
-	resp, err := s.chatModel.Generate(ctx, messages)
+func (s *openAIChatService) GenerateText(ctx context.Context, messages []*schema.Message, opts ...model.Option) (string, error) {
+	resp, err := s.chatModel.Generate(ctx, messages, opts...)
 	if err != nil {
 		return "", fmt.Errorf("failed to generate text: %w", err)
 	}
@@ -71,7 +75,7 @@ func (s *LLMService) GenerateText(ctx context.Context, messages []*schema.Messag
 }
 
 // GenerateStream 生成流式响应
-func (s *LLMService) GenerateStream(ctx context.Context, messages []*schema.Message, callback func(chunk string) error, opts ...model.Option) error {
+func (s *openAIChatService) GenerateStream(ctx context.Context, messages []*schema.Message, callback func(chunk string) error, opts ...model.Option) error {
 	stream, err := s.chatModel.Stream(ctx, messages, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to create stream: %w", err)
@@ -97,7 +101,7 @@ func (s *LLMService) GenerateStream(ctx context.Context, messages []*schema.Mess
 }
 
 // GenerateWithTools 使用工具调用生成（非流式）
-func (s *LLMService) GenerateWithTools(ctx context.Context, messages []*schema.Message, tools []*schema.ToolInfo) (*schema.Message, error) {
+func (s *openAIChatService) GenerateWithTools(ctx context.Context, messages []*schema.Message, tools []*schema.ToolInfo) (*schema.Message, error) {
 	opts := []model.Option{
 		model.WithTools(tools),
 	}
@@ -111,7 +115,7 @@ func (s *LLMService) GenerateWithTools(ctx context.Context, messages []*schema.M
 }
 
 // GenerateWithToolChoice 使用工具调用生成，并强制模型必须调用工具
-func (s *LLMService) GenerateWithToolChoice(ctx context.Context, messages []*schema.Message, tools []*schema.ToolInfo, choice schema.ToolChoice) (*schema.Message, error) {
+func (s *openAIChatService) GenerateWithToolChoice(ctx context.Context, messages []*schema.Message, tools []*schema.ToolInfo, choice schema.ToolChoice) (*schema.Message, error) {
 	opts := []model.Option{
 		model.WithTools(tools),
 		model.WithToolChoice(choice),
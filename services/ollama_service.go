@@ -0,0 +1,300 @@
+package services
+
+import (
+	"blog-server-go/config"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+)
+
+// ollamaChatService Ollama 原生 /api/chat 接口的对话模型
+type ollamaChatService struct {
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+func newOllamaChatService(cfg config.ModelConfig) *ollamaChatService {
+	// 本地模型首次加载较慢，超时交给调用方的 ctx 控制
+	return &ollamaChatService{baseURL: cfg.BaseURL, model: cfg.Model, client: &http.Client{}}
+}
+
+func (s *ollamaChatService) Model() string {
+	return s.model
+}
+
+type ollamaToolCall struct {
+	Function struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	} `json:"function"`
+}
+
+type ollamaMessage struct {
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	ToolCalls []ollamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+type ollamaTool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Parameters  any    `json:"parameters"`
+	} `json:"function"`
+}
+
+type ollamaChatRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Tools    []ollamaTool    `json:"tools,omitempty"`
+	Stream   bool            `json:"stream"`
+	Options  map[string]any  `json:"options,omitempty"`
+}
+
+type ollamaChatResponse struct {
+	Message ollamaMessage `json:"message"`
+	Done    bool          `json:"done"`
+	Error   string        `json:"error"`
+}
+
+// newRequest 将 eino 的消息和选项转换为 Ollama 请求；禁止调用工具时不传工具
+func (s *ollamaChatService) newRequest(messages []*schema.Message, stream bool, opts []model.Option) (*ollamaChatRequest, error) {
+	options := model.GetCommonOptions(nil, opts...)
+	req := &ollamaChatRequest{Model: s.model, Stream: stream, Options: map[string]any{}}
+	if options.Model != nil {
+		req.Model = *options.Model
+	}
+	if options.Temperature != nil {
+		req.Options["temperature"] = *options.Temperature
+	}
+	if options.MaxTokens != nil {
+		req.Options["num_predict"] = *options.MaxTokens
+	}
+	if options.TopP != nil {
+		req.Options["top_p"] = *options.TopP
+	}
+	if len(options.Stop) > 0 {
+		req.Options["stop"] = options.Stop
+	}
+
+	for _, message := range messages {
+		converted := ollamaMessage{Role: string(message.Role), Content: message.Content, ToolName: message.ToolName}
+		for _, call := range message.ToolCalls {
+			var toolCall ollamaToolCall
+			toolCall.Function.Name = call.Function.Name
+			toolCall.Function.Arguments = json.RawMessage("{}")
+			if json.Valid([]byte(call.Function.Arguments)) {
+				toolCall.Function.Arguments = json.RawMessage(call.Function.Arguments)
+			}
+			converted.ToolCalls = append(converted.ToolCalls, toolCall)
+		}
+		req.Messages = append(req.Messages, converted)
+	}
+
+	if options.ToolChoice != nil && *options.ToolChoice == schema.ToolChoiceForbidden {
+		return req, nil
+	}
+	for _, info := range options.Tools {
+		var tool ollamaTool
+		tool.Type = "function"
+		tool.Function.Name = info.Name
+		tool.Function.Description = info.Desc
+		tool.Function.Parameters = map[string]any{"type": "object", "properties": map[string]any{}}
+		if info.ParamsOneOf != nil {
+			parameters, err := info.ParamsOneOf.ToJSONSchema()
+			if err != nil {
+				return nil, fmt.Errorf("invalid parameters of tool %s: %w", info.Name, err)
+			}
+			tool.Function.Parameters = parameters
+		}
+		req.Tools = append(req.Tools, tool)
+	}
+	return req, nil
+}
+
+func (s *ollamaChatService) send(ctx context.Context, req *ollamaChatRequest) (*http.Response, error) {
+	jsonBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/api/chat", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %s", string(respBody))
+	}
+	return resp, nil
+}
+
+func (s *ollamaChatService) generate(ctx context.Context, messages []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	req, err := s.newRequest(messages, false, opts)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result ollamaChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("API error: %s", result.Error)
+	}
+
+	// Ollama 不返回工具调用 ID，按顺序生成
+	var toolCalls []schema.ToolCall
+	for i, call := range result.Message.ToolCalls {
+		toolCalls = append(toolCalls, schema.ToolCall{
+			ID:   fmt.Sprintf("call_%d", i),
+			Type: "function",
+			Function: schema.FunctionCall{
+				Name:      call.Function.Name,
+				Arguments: string(call.Function.Arguments),
+			},
+		})
+	}
+	return schema.AssistantMessage(result.Message.Content, toolCalls), nil
+}
+
+// GenerateText 生成文本（非流式）
+func (s *ollamaChatService) GenerateText(ctx context.Context, messages []*schema.Message, opts ...model.Option) (string, error) {
+	resp, err := s.generate(ctx, messages, opts...)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate text: %w", err)
+	}
+	return resp.Content, nil
+}
+
+// GenerateStream 生成流式响应，Ollama 以每行一个 JSON 的形式返回
+func (s *ollamaChatService) GenerateStream(ctx context.Context, messages []*schema.Message, callback func(chunk string) error, opts ...model.Option) error {
+	req, err := s.newRequest(messages, true, opts)
+	if err != nil {
+		return err
+	}
+	resp, err := s.send(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to create stream: %w", err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var chunk ollamaChatResponse
+		if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil {
+			return fmt.Errorf("stream error: %w", err)
+		}
+		if chunk.Error != "" {
+			return fmt.Errorf("stream error: %s", chunk.Error)
+		}
+		if chunk.Message.Content != "" {
+			if err := callback(chunk.Message.Content); err != nil {
+				return fmt.Errorf("callback error: %w", err)
+			}
+		}
+		if chunk.Done {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stream error: %w", err)
+	}
+	return nil
+}
+
+// GenerateWithTools 使用工具调用生成（非流式）
+func (s *ollamaChatService) GenerateWithTools(ctx context.Context, messages []*schema.Message, tools []*schema.ToolInfo) (*schema.Message, error) {
+	resp, err := s.generate(ctx, messages, model.WithTools(tools))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate with tools: %w", err)
+	}
+	return resp, nil
+}
+
+// GenerateWithToolChoice Ollama 不支持强制调用工具，forced 时与 GenerateWithTools 相同
+func (s *ollamaChatService) GenerateWithToolChoice(ctx context.Context, messages []*schema.Message, tools []*schema.ToolInfo, choice schema.ToolChoice) (*schema.Message, error) {
+	resp, err := s.generate(ctx, messages, model.WithTools(tools), model.WithToolChoice(choice))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate with tools: %w", err)
+	}
+	return resp, nil
+}
+
+// ollamaEmbeddingService Ollama 原生 /api/embed 接口的向量模型
+type ollamaEmbeddingService struct {
+	baseURL    string
+	model      string
+	dimensions int
+	client     *http.Client
+}
+
+func newOllamaEmbeddingService(cfg config.ModelConfig, dimensions int) *ollamaEmbeddingService {
+	return &ollamaEmbeddingService{baseURL: cfg.BaseURL, model: cfg.Model, dimensions: dimensions, client: &http.Client{Timeout: embeddingRequestTimeout}}
+}
+
+func (s *ollamaEmbeddingService) Model() string {
+	return s.model
+}
+
+func (s *ollamaEmbeddingService) Dimensions() int {
+	return s.dimensions
+}
+
+// Embed 调用 /api/embed 批量生成向量
+func (s *ollamaEmbeddingService) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	jsonBody, err := json.Marshal(map[string]any{"model": s.model, "input": texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", s.baseURL+"/api/embed", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %s", string(respBody))
+	}
+
+	var result struct {
+		Embeddings [][]float32 `json:"embeddings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(result.Embeddings))
+	}
+	return result.Embeddings, nil
+}
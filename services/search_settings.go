@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// searchSettingsKey 搜索设置单独保存，不放在公开读写的 blog_config 中
+const searchSettingsKey = "searchSettings"
+
+var (
+	// ArticleSearchableAttributes 参与搜索的字段
+	ArticleSearchableAttributes = []string{"title", "content", "tags"}
+	// ArticleFilterableAttributes 可过滤、可统计分面的字段
+	ArticleFilterableAttributes = []string{"tags", "created_at", "view_count"}
+	// ArticleSortableAttributes 可排序的字段
+	ArticleSortableAttributes = []string{"created_at", "view_count"}
+	// DefaultRankingRules Meilisearch 默认的排序规则
+	DefaultRankingRules = []string{"words", "typo", "proximity", "attribute", "sort", "exactness"}
+)
+
+// SearchSettings 由管理员维护的搜索设置，每次重建索引时重新应用
+type SearchSettings struct {
+	Synonyms     map[string][]string `json:"synonyms"`
+	StopWords    []string            `json:"stopWords"`
+	RankingRules []string            `json:"rankingRules"`
+}
+
+// LoadSearchSettings 读取搜索设置，未配置时返回空设置
+func LoadSearchSettings(ctx context.Context, rdb *redis.Client) (SearchSettings, error) {
+	var settings SearchSettings
+	raw, err := rdb.Get(ctx, searchSettingsKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return settings, err
+	}
+	if raw != "" {
+		if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+			return settings, err
+		}
+	}
+	return settings.Normalize()
+}
+
+// SaveSearchSettings 规范化并保存搜索设置
+func SaveSearchSettings(ctx context.Context, rdb *redis.Client, settings SearchSettings) (SearchSettings, error) {
+	settings, err := settings.Normalize()
+	if err != nil {
+		return settings, err
+	}
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return settings, err
+	}
+	return settings, rdb.Set(ctx, searchSettingsKey, data, 0).Err()
+}
+
+// Normalize 去除空白和重复项，并校验排序规则
+func (s SearchSettings) Normalize() (SearchSettings, error) {
+	normalized := SearchSettings{
+		Synonyms:     make(map[string][]string, len(s.Synonyms)),
+		StopWords:    uniqueTrimmed(s.StopWords),
+		RankingRules: uniqueTrimmed(s.RankingRules),
+	}
+	for word, synonyms := range s.Synonyms {
+		word = strings.TrimSpace(word)
+		synonyms = uniqueTrimmed(synonyms)
+		if word == "" || len(synonyms) == 0 {
+			continue
+		}
+		normalized.Synonyms[word] = synonyms
+	}
+
+	builtin := make(map[string]bool, len(DefaultRankingRules))
+	for _, rule := range DefaultRankingRules {
+		builtin[rule] = true
+	}
+	sortable := make(map[string]bool, len(ArticleSortableAttributes))
+	for _, attribute := range ArticleSortableAttributes {
+		sortable[attribute] = true
+	}
+	for _, rule := range normalized.RankingRules {
+		if builtin[rule] {
+			continue
+		}
+		// 自定义规则形如 view_count:desc，字段必须可排序
+		attribute, direction, ok := strings.Cut(rule, ":")
+		if !ok || !sortable[attribute] || (direction != "asc" && direction != "desc") {
+			return normalized, fmt.Errorf("invalid ranking rule %q", rule)
+		}
+	}
+	return normalized, nil
+}
+
+func uniqueTrimmed(values []string) []string {
+	result := make([]string, 0, len(values))
+	seen := make(map[string]bool, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
@@ -0,0 +1,41 @@
+package tasks
+
+import (
+	"blog-server-go/kafka"
+	"blog-server-go/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// publishScheduledArticles 将到达发布时间的定时文章改为已发布，并通知前端重新验证
+func publishScheduledArticles(db *gorm.DB, producer *kafka.Producer) {
+	var ids []models.SnowflakeID
+	err := db.Model(&models.Article{}).
+		Where("is_deleted", false).
+		Where("status = ? AND publish_at <= ?", models.ArticleStatusScheduled, time.Now()).
+		Pluck("id", &ids).Error
+	if err != nil {
+		log.Errorf("Failed to query scheduled articles: %v", err)
+		return
+	}
+
+	for _, id := range ids {
+		result := db.Model(&models.Article{}).
+			Where("id = ? AND status = ?", id, models.ArticleStatusScheduled).
+			Updates(map[string]interface{}{
+				"status":    models.ArticleStatusPublished,
+				"is_active": true,
+			})
+		if result.Error != nil {
+			log.Errorf("Failed to publish scheduled article %s: %v", id, result.Error)
+			continue
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		log.Infof("Scheduled article published: %s", id)
+		producer.ProduceMessage(kafka.ArticleUpdateTopic, "id", string(id))
+	}
+}
@@ -0,0 +1,28 @@
+package tasks
+
+import (
+	"blog-server-go/services"
+	"time"
+
+	"github.com/gofiber/fiber/v2/log"
+	"gorm.io/gorm"
+)
+
+// purgeExpiredTrash 永久删除在回收站中超过保留天数的文章
+func purgeExpiredTrash(db *gorm.DB) {
+	ids, err := services.ExpiredTrashArticleIDs(db, time.Now())
+	if err != nil {
+		log.Errorf("Failed to query expired trash articles: %v", err)
+		return
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	purged, err := services.PurgeArticles(db, ids)
+	if err != nil {
+		log.Errorf("Failed to purge expired trash articles: %v", err)
+		return
+	}
+	log.Infof("Purged %d expired trash articles", len(purged))
+}
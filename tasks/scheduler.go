@@ -1,15 +1,17 @@
 package tasks
 
 import (
+	"blog-server-go/kafka"
 	"fmt"
 	"github.com/go-co-op/gocron"
+	"gorm.io/gorm"
 	"time"
 )
 
 func task() {
 	fmt.Println("I am runnning task.")
 }
-func StartCronJobs() func() {
+func StartCronJobs(db *gorm.DB, producer *kafka.Producer) func() {
 	scheduler := gocron.NewScheduler(time.UTC)
 
 	// 示例任务: 每隔10秒输出文本
@@ -23,6 +25,10 @@ func StartCronJobs() func() {
 
 	// 每天的特定时间执行任务
 	scheduler.Cron("0 18 * * *").Do(task)
+	// 每分钟发布到期的定时文章
+	scheduler.Every(1).Minute().Do(publishScheduledArticles, db, producer)
+	// 每天清理回收站中超过保留天数的文章
+	scheduler.Cron("30 3 * * *").Do(purgeExpiredTrash, db)
 	scheduler.StartAsync()
 
 	return func() {